@@ -0,0 +1,111 @@
+// cmd/api/access_tokens.go
+package main
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"strconv"
+	"time"
+
+	"GopherSocial/internal/auth"
+	"GopherSocial/internal/store"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Todos los tokens personales empiezan así, para distinguirlos de un JWT de un vistazo.
+const personalTokenPrefix = "gsp_"
+
+// Scopes que se pueden conceder a un token personal.
+const (
+	scopeFeedRead      = "feed:read"
+	scopePostsRead     = "posts:read"
+	scopePostsWrite    = "posts:write"
+	scopeCommentsWrite = "comments:write"
+	scopeFollowsWrite  = "follows:write"
+	scopeUsersRead     = "users:read"
+)
+
+type CreateAccessTokenPayload struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,unique,dive,oneof=feed:read posts:read posts:write comments:write follows:write users:read"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"required,gte=1,lte=365"`
+}
+
+// createAccessTokenHandler crea un token personal. El token en claro solo se
+// devuelve en esta respuesta; después solo guardamos su hash.
+func (app *application) createAccessTokenHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	var payload CreateAccessTokenPayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	random, err := auth.RandomString(32)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	plainToken := personalTokenPrefix + random
+	hash := sha256.Sum256([]byte(plainToken))
+
+	token := &store.PersonalAccessToken{
+		UserID: user.ID,
+		Name:   payload.Name,
+		Scopes: payload.Scopes,
+	}
+
+	exp := time.Hour * 24 * time.Duration(payload.ExpiresInDays)
+	if err := app.store.AccessTokens.Create(r.Context(), token, hash[:], exp); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, map[string]any{
+		"token":        plainToken,
+		"access_token": token,
+	})
+}
+
+// listAccessTokensHandler lista los tokens personales del usuario (sin el token en sí).
+func (app *application) listAccessTokensHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	tokens, err := app.store.AccessTokens.GetByUserID(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, tokens)
+}
+
+// deleteAccessTokenHandler revoca uno de los tokens personales del usuario.
+func (app *application) deleteAccessTokenHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	tokenID, err := strconv.ParseInt(chi.URLParam(r, "tokenID"), 10, 64)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if err := app.store.AccessTokens.Delete(r.Context(), user.ID, tokenID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,240 @@
+// cmd/api/admin_users.go
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"GopherSocial/internal/store"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type UpdateUserRolePayload struct {
+	Role string `json:"role" validate:"required,max=255"`
+}
+
+type BanUserPayload struct {
+	Reason string     `json:"reason" validate:"required,max=500"`
+	Until  *time.Time `json:"until"` // Opcional: sin fecha la suspensión es indefinida
+}
+
+// loadTargetUser carga el usuario del userID de la URL sobre el que actúa un admin.
+func (app *application) loadTargetUser(w http.ResponseWriter, r *http.Request) (*store.User, bool) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	user, err := app.store.Users.GetByID(r.Context(), userID)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return nil, false
+	}
+
+	return user, true
+}
+
+// outranks indica si actor está por encima de target en la jerarquía de roles.
+// Un admin solo puede actuar sobre usuarios de un nivel inferior al suyo.
+func outranks(actor, target *store.User) bool {
+	return actor.Role.Level > target.Role.Level
+}
+
+// audit registra una acción administrativa. Un fallo aquí no deshace la acción.
+func (app *application) audit(r *http.Request, action string, target *store.User, metadata map[string]any) {
+	actor := r.Context().Value(userCtxKey).(*store.User)
+
+	log := &store.AuditLog{
+		ActorID:    &actor.ID,
+		Action:     action,
+		TargetType: "user",
+		TargetID:   target.ID,
+		Metadata:   metadata,
+	}
+	if err := app.store.AuditLogs.Create(r.Context(), log); err != nil {
+		app.logger.Printf("ERROR: no se pudo guardar el log de auditoría: %s", err)
+	}
+}
+
+// updateUserRoleHandler asciende o degrada a un usuario cambiando su rol.
+func (app *application) updateUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	actor := r.Context().Value(userCtxKey).(*store.User)
+
+	target, ok := app.loadTargetUser(w, r)
+	if !ok {
+		return
+	}
+
+	var payload UpdateUserRolePayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// Evitamos que un admin se quite sus propios permisos por accidente.
+	if target.ID == actor.ID {
+		app.badRequestResponse(w, r, errors.New("no puedes cambiar tu propio rol"))
+		return
+	}
+
+	if !outranks(actor, target) {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	role, err := app.store.Roles.GetByName(r.Context(), payload.Role)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.badRequestResponse(w, r, errors.New("el rol no existe"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	// Tampoco puede conceder un rol por encima del suyo.
+	if role.Level > actor.Role.Level {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	if err := app.store.Users.UpdateRole(r.Context(), target.ID, role.ID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	// Borramos la caché para que AuthTokenMiddleware vea el nuevo rol ya.
+	app.cacheStorage.Users.Delete(r.Context(), target.ID)
+
+	app.audit(r, store.AuditUserRoleUpdated, target, map[string]any{
+		"from": target.Role.Name,
+		"to":   role.Name,
+	})
+
+	target.Role = *role
+	app.jsonResponse(w, http.StatusOK, target)
+}
+
+// banUserHandler suspende a un usuario y cierra todas sus sesiones.
+func (app *application) banUserHandler(w http.ResponseWriter, r *http.Request) {
+	actor := r.Context().Value(userCtxKey).(*store.User)
+
+	target, ok := app.loadTargetUser(w, r)
+	if !ok {
+		return
+	}
+
+	var payload BanUserPayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if payload.Until != nil && !payload.Until.After(time.Now()) {
+		app.badRequestResponse(w, r, errors.New("until debe ser una fecha futura"))
+		return
+	}
+
+	if target.ID == actor.ID {
+		app.badRequestResponse(w, r, errors.New("no puedes suspender tu propia cuenta"))
+		return
+	}
+
+	if !outranks(actor, target) {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	if err := app.store.Users.Ban(r.Context(), target.ID, payload.Reason, payload.Until); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.cacheStorage.Users.Delete(r.Context(), target.ID)
+
+	metadata := map[string]any{"reason": payload.Reason}
+	if payload.Until != nil {
+		metadata["until"] = payload.Until.UTC().Format(time.RFC3339)
+	}
+	app.audit(r, store.AuditUserBanned, target, metadata)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// unbanUserHandler levanta la suspensión de un usuario.
+func (app *application) unbanUserHandler(w http.ResponseWriter, r *http.Request) {
+	actor := r.Context().Value(userCtxKey).(*store.User)
+
+	target, ok := app.loadTargetUser(w, r)
+	if !ok {
+		return
+	}
+
+	if !outranks(actor, target) {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	if err := app.store.Users.Unban(r.Context(), target.ID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.cacheStorage.Users.Delete(r.Context(), target.ID)
+
+	app.audit(r, store.AuditUserUnbanned, target, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listAuditLogsHandler lista el log de auditoría (?search= filtra por acción).
+func (app *application) listAuditLogsHandler(w http.ResponseWriter, r *http.Request) {
+	q, err := app.readPaginatedQuery(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	logs, nextCursor, err := app.store.AuditLogs.GetAll(r.Context(), q)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.paginatedResponse(w, http.StatusOK, logs, nextCursor)
+}
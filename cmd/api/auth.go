@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"crypto/sha256" // <--- Necesario para el token
 	"fmt"
 	"net/http"
@@ -47,7 +48,7 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 	hash := sha256.Sum256([]byte(plainToken))
 	tokenHash := hash[:]
 
-	err := app.store.Users.CreateAndInvite(r.Context(), user, tokenHash, time.Hour*72)
+	err := app.store.Users.CreateAndInvite(r.Context(), user, tokenHash, app.config.mail.exp)
 	if err != nil {
 		switch err {
 		case store.ErrDuplicateEmail, store.ErrDuplicateUsername:
@@ -57,25 +58,64 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 		}
 		return
 	}
-	go func() {
-		activationURL := fmt.Sprintf("http://localhost:8080/v1/users/activate/%s", plainToken)
-		data := map[string]string{
-			"ActivationURL": activationURL,
-			"Username":      user.Username,
-		}
 
-		// Enviamos el correo en segundo plano
-		_, err := app.mailer.Send("user_invitation.tmpl", user.Username, user.Email, data)
-		if err != nil {
-			// Si falla, solo lo registramos. El usuario ya recibió su respuesta.
-			app.logger.Printf("ERROR: no se pudo enviar el correo de bienvenida en segundo plano: %s", err)
-		}
-	}() // Los paréntesis finales `()` son los que ejecutan la función anónima.
+	// Enviamos el correo en segundo plano
+	go app.sendActivationEmail(user, plainToken)
 
 	// Respondemos al usuario INMEDIATAMENTE, sin esperar el correo.
 	app.jsonResponse(w, http.StatusCreated, user)
 }
 
+// sendActivationEmail envía el enlace de activación. Está pensado para
+// llamarse en una goroutine: si falla, solo lo registramos.
+func (app *application) sendActivationEmail(user *store.User, plainToken string) {
+	data := map[string]string{
+		"ActivationURL": app.activationURL(plainToken),
+		"Username":      user.Username,
+	}
+
+	_, err := app.mailer.Send("user_invitation.tmpl", user.Username, user.Email, data)
+	if err != nil {
+		app.logger.Printf("ERROR: no se pudo enviar el correo de bienvenida en segundo plano: %s", err)
+	}
+}
+
+type ResendActivationPayload struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+// resendActivationHandler genera una nueva invitación para una cuenta sin activar
+// (la anterior deja de servir) y vuelve a enviar el correo.
+// Siempre responde lo mismo para no revelar qué emails están registrados.
+func (app *application) resendActivationHandler(w http.ResponseWriter, r *http.Request) {
+	var payload ResendActivationPayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	plainToken := uuid.New().String()
+	hash := sha256.Sum256([]byte(plainToken))
+
+	user, err := app.store.Users.RotateInvitation(r.Context(), payload.Email, hash[:], app.config.mail.exp)
+	switch err {
+	case nil:
+		go app.sendActivationEmail(user, plainToken)
+	case store.ErrNotFound:
+		// No hay ninguna cuenta pendiente con ese email: respondemos igual.
+	default:
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusAccepted, map[string]string{"message": "si hay una cuenta pendiente de activar con ese email, recibirás un nuevo enlace"})
+}
+
 type CreateUserTokenPayload struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
@@ -93,31 +133,260 @@ func (app *application) createTokenHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	ip := clientIP(r)
+
+	// Si la cuenta o la IP están bloqueadas ni siquiera comprobamos la contraseña.
+	lockedFor, err := app.loginLockedFor(r.Context(), payload.Email, ip)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if lockedFor > 0 {
+		app.auditLoginFailure(r.Context(), payload.Email, ip, nil, store.LoginFailureLocked)
+		app.accountLockedResponse(w, r, lockedFor)
+		return
+	}
+
 	user, err := app.store.Users.GetByEmail(r.Context(), payload.Email)
 	if err != nil {
+		app.registerLoginFailure(r.Context(), payload.Email, ip, nil, store.LoginFailureUnknownEmail)
 		app.unauthorizedErrorResponse(w, r)
 		return
 	}
 
 	if err := user.Password.Compare(payload.Password); err != nil {
+		app.registerLoginFailure(r.Context(), payload.Email, ip, user, store.LoginFailureBadPassword)
 		app.unauthorizedErrorResponse(w, r)
 		return
 	}
 
-	claims := jwt.RegisteredClaims{
-		Subject:   fmt.Sprintf("%d", user.ID),
-		Issuer:    "gophersocial",
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	// La contraseña es correcta. Si hay 2FA, el contador se reinicia al superar el segundo paso.
+	if !user.TwoFactorEnabled {
+		app.resetLoginFailures(r.Context(), user.Email)
+	}
+
+	app.completeLogin(w, r, user)
+}
+
+// completeLogin termina cualquier login en el que ya verificamos la identidad
+// del usuario (contraseña, proveedor OIDC...): emite los tokens o, si tiene 2FA
+// activado, devuelve el reto que hay que canjear en /v1/authentication/token/2fa.
+func (app *application) completeLogin(w http.ResponseWriter, r *http.Request, user *store.User) {
+	if user.IsBanned() {
+		app.bannedResponse(w, r, user)
+		return
+	}
+
+	if user.TwoFactorEnabled {
+		challenge, err := app.generateTwoFactorChallenge(user)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		app.jsonResponse(w, http.StatusOK, map[string]any{
+			"two_factor_required": true,
+			"challenge_token":     challenge,
+		})
+		return
 	}
 
-	token, err := app.authenticator.GenerateToken(claims)
+	tokens, err := app.issueTokens(r, user)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	app.jsonResponse(w, http.StatusCreated, map[string]string{"token": token})
+	app.jsonResponse(w, http.StatusCreated, tokens)
+}
+
+// tokenResponse es lo que devolvemos al iniciar sesión o refrescar la sesión.
+type tokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// accessClaims son los claims de nuestros access tokens. SessionID ("sid")
+// permite invalidar todos los tokens de una sesión al cerrarla.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	SessionID string `json:"sid,omitempty"`
+}
+
+// generateAccessToken firma un access token de vida corta para el usuario.
+// Cada token lleva un jti único para poder revocarlo individualmente.
+func (app *application) generateAccessToken(user *store.User, sessionID string) (string, error) {
+	claims := accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   fmt.Sprintf("%d", user.ID),
+			Issuer:    app.authenticator.Issuer(),
+			Audience:  jwt.ClaimStrings{app.authenticator.Audience()},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(app.config.auth.exp)),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+		SessionID: sessionID,
+	}
+
+	return app.authenticator.GenerateToken(claims)
+}
+
+// newRefreshToken genera un refresh token en claro y su hash para guardarlo.
+func newRefreshToken() (string, []byte) {
+	plainToken := uuid.New().String()
+	hash := sha256.Sum256([]byte(plainToken))
+	return plainToken, hash[:]
+}
+
+// issueTokens abre una nueva sesión para el dispositivo de la petición y
+// devuelve su access token y su primer refresh token.
+// Iniciar sesión en una cuenta borrada que aún no se purgó la recupera.
+func (app *application) issueTokens(r *http.Request, user *store.User) (*tokenResponse, error) {
+	if user.DeletedAt != nil {
+		if err := app.store.Users.Restore(r.Context(), user.ID); err != nil {
+			return nil, err
+		}
+		user.DeletedAt = nil
+		app.cacheStorage.Users.Delete(r.Context(), user.ID)
+	}
+
+	userAgent := r.UserAgent()
+	session := &store.Session{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Device:    deviceFromUserAgent(userAgent),
+		UserAgent: userAgent,
+		IP:        clientIP(r),
+	}
+
+	refreshToken, refreshHash := newRefreshToken()
+	if err := app.store.Sessions.Create(r.Context(), session, refreshHash, app.config.auth.refreshExp); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := app.generateAccessToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenResponse{Token: accessToken, RefreshToken: refreshToken}, nil
+}
+
+type RefreshTokenPayload struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// refreshTokenHandler cambia un refresh token válido por un nuevo par de tokens.
+// El refresh token usado queda consumido: presentarlo otra vez revoca toda la familia.
+func (app *application) refreshTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var payload RefreshTokenPayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	hash := sha256.Sum256([]byte(payload.RefreshToken))
+	newToken, newHash := newRefreshToken()
+
+	current, err := app.store.RefreshTokens.Rotate(r.Context(), hash[:], newHash, app.config.auth.refreshExp)
+	if err != nil {
+		switch err {
+		case store.ErrTokenReused:
+			app.logger.Printf("WARN: refresh token reutilizado, sesión %s revocada", current.FamilyID)
+			if err := app.cacheStorage.Tokens.RevokeSession(r.Context(), current.FamilyID, app.config.auth.exp); err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+			app.unauthorizedErrorResponse(w, r)
+		case store.ErrNotFound:
+			app.unauthorizedErrorResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	user, err := app.store.Users.GetByID(r.Context(), current.UserID)
+	if err != nil || !user.IsActive {
+		app.unauthorizedErrorResponse(w, r)
+		return
+	}
+	if user.IsBanned() {
+		app.bannedResponse(w, r, user)
+		return
+	}
+
+	// El id de la familia es el id de la sesión.
+	if err := app.store.Sessions.Touch(r.Context(), current.FamilyID, clientIP(r), r.UserAgent()); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	accessToken, err := app.generateAccessToken(user, current.FamilyID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, tokenResponse{Token: accessToken, RefreshToken: newToken})
+}
+
+// logoutHandler revoca el access token actual y cierra la sesión del refresh token recibido.
+func (app *application) logoutHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+	claims, _ := r.Context().Value(claimsCtxKey).(jwt.MapClaims)
+
+	var payload RefreshTokenPayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	hash := sha256.Sum256([]byte(payload.RefreshToken))
+	if err := app.store.RefreshTokens.RevokeFamilyByToken(r.Context(), user.ID, hash[:]); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.revokeAccessToken(r.Context(), claims); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if sid, _ := claims["sid"].(string); sid != "" {
+		if err := app.cacheStorage.Tokens.RevokeSession(r.Context(), sid, app.config.auth.exp); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// revokeAccessToken añade el jti del token a la lista de revocados hasta que expire.
+// claims puede ser nil (petición autenticada con un token personal).
+func (app *application) revokeAccessToken(ctx context.Context, claims jwt.MapClaims) error {
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return nil
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return err
+	}
+
+	return app.cacheStorage.Tokens.Revoke(ctx, jti, time.Until(exp.Time))
 }
 
 // ¡NUEVO HANDLER!
@@ -0,0 +1,152 @@
+// cmd/api/blocks.go
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"GopherSocial/internal/store"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// blockUserHandler bloquea a un usuario: ninguno de los dos verá las
+// publicaciones ni los comentarios del otro y se dejan de seguir.
+func (app *application) blockUserHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	blockedID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Blocks.Block(r.Context(), user.ID, blockedID); err != nil {
+		switch err {
+		case store.ErrSelfBlock:
+			app.badRequestResponse(w, r, errors.New("no puedes bloquearte a ti mismo"))
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		case store.ErrConflict:
+			app.conflictResponse(w, r, errors.New("ya bloqueaste a este usuario"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	// El bloqueo es en ambas direcciones: ninguno debe seguir sugiriéndose al otro.
+	app.cacheStorage.Suggestions.Delete(r.Context(), user.ID)
+	app.cacheStorage.Suggestions.Delete(r.Context(), blockedID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) unblockUserHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	blockedID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Blocks.Unblock(r.Context(), user.ID, blockedID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r) // No estaba bloqueado
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.cacheStorage.Suggestions.Delete(r.Context(), user.ID)
+	app.cacheStorage.Suggestions.Delete(r.Context(), blockedID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// muteUserHandler silencia a un usuario: sus publicaciones dejan de salir en
+// el feed de quien silencia, sin que el otro lo note.
+func (app *application) muteUserHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	mutedID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Mutes.Mute(r.Context(), user.ID, mutedID); err != nil {
+		switch err {
+		case store.ErrSelfBlock:
+			app.badRequestResponse(w, r, errors.New("no puedes silenciarte a ti mismo"))
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		case store.ErrConflict:
+			app.conflictResponse(w, r, errors.New("ya silenciaste a este usuario"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.cacheStorage.Suggestions.Delete(r.Context(), user.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) unmuteUserHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	mutedID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Mutes.Unmute(r.Context(), user.ID, mutedID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r) // No estaba silenciado
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.cacheStorage.Suggestions.Delete(r.Context(), user.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listBlockedHandler lista los usuarios bloqueados por el usuario autenticado.
+func (app *application) listBlockedHandler(w http.ResponseWriter, r *http.Request) {
+	app.listUserSummaries(w, r, app.store.Blocks.GetBlocked)
+}
+
+// listMutedHandler lista los usuarios silenciados por el usuario autenticado.
+func (app *application) listMutedHandler(w http.ResponseWriter, r *http.Request) {
+	app.listUserSummaries(w, r, app.store.Mutes.GetMuted)
+}
+
+func (app *application) listUserSummaries(w http.ResponseWriter, r *http.Request, list func(context.Context, int64, store.PaginatedQuery) ([]store.UserSummary, string, error)) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	q, err := app.readPaginatedQuery(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	users, nextCursor, err := list(r.Context(), user.ID, q)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.paginatedResponse(w, http.StatusOK, users, nextCursor)
+}
@@ -3,11 +3,13 @@ package main
 
 import (
 	"GopherSocial/internal/store"
+	"errors"
 	"net/http"
 )
 
 type CreateCommentPayload struct {
-	Content string `json:"content" validate:"required,max=1000"`
+	Content  string `json:"content"   validate:"required,max=1000"`
+	ParentID *int64 `json:"parent_id" validate:"omitempty,gt=0"` // Para responder a otro comentario
 }
 
 func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Request) {
@@ -27,15 +29,125 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 	}
 
 	comment := &store.Comment{
-		Content: payload.Content,
-		PostID:  post.ID,
-		UserID:  user.ID,
+		Content:  payload.Content,
+		PostID:   post.ID,
+		UserID:   user.ID,
+		ParentID: payload.ParentID,
 	}
 
 	if err := app.store.Comments.Create(r.Context(), comment); err != nil {
-		app.internalServerError(w, r, err)
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r) // El comentario al que responde no existe en este post
+		case store.ErrCommentTooDeep:
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
 		return
 	}
 
 	app.jsonResponse(w, http.StatusCreated, comment)
 }
+
+// listCommentsHandler devuelve los comentarios de primer nivel del post, paginados,
+// con su número de respuestas.
+func (app *application) listCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	post := r.Context().Value(postCtxKey).(*store.Post)
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	query, err := app.readPaginatedQuery(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	comments, nextCursor, err := app.store.Comments.GetByPostID(r.Context(), post.ID, user.ID, query)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.paginatedResponse(w, http.StatusOK, comments, nextCursor)
+}
+
+// listCommentRepliesHandler devuelve las respuestas directas a un comentario, paginadas.
+func (app *application) listCommentRepliesHandler(w http.ResponseWriter, r *http.Request) {
+	comment := r.Context().Value(commentCtxKey).(*store.Comment)
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	query, err := app.readPaginatedQuery(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	replies, nextCursor, err := app.store.Comments.GetReplies(r.Context(), comment.ID, user.ID, query)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.paginatedResponse(w, http.StatusOK, replies, nextCursor)
+}
+
+// errCommentEditConflict se devuelve cuando la versión del comentario ya no es la actual.
+var errCommentEditConflict = errors.New("el comentario fue modificado por otra petición, vuelve a cargarlo")
+
+type UpdateCommentPayload struct {
+	Content string `json:"content" validate:"required,max=1000"`
+	Version *int   `json:"version"` // Opcional: la versión que el cliente editó
+}
+
+// updateCommentHandler edita el contenido de un comentario (autor o moderador).
+func (app *application) updateCommentHandler(w http.ResponseWriter, r *http.Request) {
+	comment := r.Context().Value(commentCtxKey).(*store.Comment)
+
+	var payload UpdateCommentPayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// Si el cliente indica la versión que editó y ya no es la actual, alguien
+	// lo cambió mientras tanto.
+	if payload.Version != nil && *payload.Version != comment.Version {
+		app.conflictResponse(w, r, errCommentEditConflict)
+		return
+	}
+
+	comment.Content = payload.Content
+
+	if err := app.store.Comments.Update(r.Context(), comment); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.conflictResponse(w, r, errCommentEditConflict)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, comment)
+}
+
+// deleteCommentHandler borra un comentario y sus respuestas (autor o moderador).
+func (app *application) deleteCommentHandler(w http.ResponseWriter, r *http.Request) {
+	comment := r.Context().Value(commentCtxKey).(*store.Comment)
+
+	if err := app.store.Comments.Delete(r.Context(), comment.ID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
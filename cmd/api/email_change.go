@@ -0,0 +1,122 @@
+// cmd/api/email_change.go
+package main
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"strings"
+
+	"GopherSocial/internal/store"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type ChangeEmailPayload struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+// changeEmailHandler envía un enlace de confirmación al nuevo email y avisa al
+// actual. El email de la cuenta no cambia hasta que se confirma. Si el nuevo
+// email ya es de otra cuenta respondemos lo mismo, para no revelar qué emails
+// están registrados, y el aviso le llega por correo a su dueño.
+func (app *application) changeEmailHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	var payload ChangeEmailPayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// La columna es citext: comparamos igual que la base de datos.
+	if strings.EqualFold(payload.Email, user.Email) {
+		app.jsonResponse(w, http.StatusOK, user)
+		return
+	}
+
+	plainToken := uuid.New().String()
+	hash := sha256.Sum256([]byte(plainToken))
+
+	err := app.store.Users.CreateEmailChange(r.Context(), user.ID, payload.Email, hash[:], app.config.auth.emailChangeExp)
+	switch err {
+	case nil:
+		go app.sendEmailChangeEmails(user, payload.Email, plainToken)
+	case store.ErrDuplicateEmail:
+		go app.sendEmailTakenEmails(user, payload.Email)
+	default:
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusAccepted, map[string]string{
+		"message": "te enviamos un enlace al nuevo email para confirmar el cambio",
+	})
+}
+
+func (app *application) sendEmailChangeEmails(user *store.User, newEmail, plainToken string) {
+	confirm := map[string]string{
+		"Username":   user.Username,
+		"ConfirmURL": app.emailChangeURL(plainToken),
+		"ExpiresIn":  app.config.auth.emailChangeExp.String(),
+	}
+	if _, err := app.mailer.Send("email_change.tmpl", user.Username, newEmail, confirm); err != nil {
+		app.logger.Printf("ERROR: no se pudo enviar el correo de confirmación del nuevo email: %s", err)
+	}
+
+	app.sendEmailChangeNotice(user, newEmail)
+}
+
+// sendEmailTakenEmails avisa al dueño de newEmail de que alguien intentó usarlo.
+// El email actual del usuario recibe el mismo aviso que si el cambio siguiera
+// adelante, así su bandeja tampoco delata que newEmail está registrado.
+func (app *application) sendEmailTakenEmails(user *store.User, newEmail string) {
+	taken := map[string]string{
+		"ForgotPasswordURL": app.forgotPasswordURL(),
+	}
+	if _, err := app.mailer.Send("email_change_taken.tmpl", "", newEmail, taken); err != nil {
+		app.logger.Printf("ERROR: no se pudo avisar al dueño del email ya registrado: %s", err)
+	}
+
+	app.sendEmailChangeNotice(user, newEmail)
+}
+
+// sendEmailChangeNotice avisa al email actual del usuario de la solicitud de cambio.
+func (app *application) sendEmailChangeNotice(user *store.User, newEmail string) {
+	notice := map[string]string{
+		"Username":          user.Username,
+		"NewEmail":          newEmail,
+		"ForgotPasswordURL": app.forgotPasswordURL(),
+	}
+	if _, err := app.mailer.Send("email_change_notice.tmpl", user.Username, user.Email, notice); err != nil {
+		app.logger.Printf("ERROR: no se pudo avisar del cambio de email: %s", err)
+	}
+}
+
+// confirmEmailChangeHandler aplica el cambio de email a partir del token del correo.
+// El token basta para autenticar la petición, igual que en la activación.
+func (app *application) confirmEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	hash := sha256.Sum256([]byte(chi.URLParam(r, "token")))
+
+	user, err := app.store.Users.ConfirmEmailChange(r.Context(), hash[:])
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		case store.ErrDuplicateEmail:
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.cacheStorage.Users.Delete(r.Context(), user.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
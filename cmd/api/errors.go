@@ -2,8 +2,12 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"net/http"
+	"time"
+
+	"GopherSocial/internal/store"
 )
 
 func (app *application) internalServerError(w http.ResponseWriter, r *http.Request, err error) {
@@ -32,7 +36,29 @@ func (app *application) forbiddenResponse(w http.ResponseWriter, r *http.Request
 	app.writeJSONError(w, http.StatusForbidden, "no tienes permiso para realizar esta acción")
 }
 
+func (app *application) twoFactorRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	app.writeJSONError(w, http.StatusForbidden, "tu rol exige activar la verificación en dos pasos")
+}
+
+// bannedResponse responde 403 a un usuario suspendido, con el motivo si lo hay.
+func (app *application) bannedResponse(w http.ResponseWriter, r *http.Request, user *store.User) {
+	message := "tu cuenta está suspendida"
+	if user.BannedUntil != nil {
+		message += " hasta el " + user.BannedUntil.UTC().Format(time.RFC3339)
+	}
+	if user.BanReason != "" {
+		message += ": " + user.BanReason
+	}
+	app.writeJSONError(w, http.StatusForbidden, message)
+}
+
 func (app *application) rateLimitExceededResponse(w http.ResponseWriter, r *http.Request) {
 	message := "límite de peticiones excedido"
 	app.writeJSONError(w, http.StatusTooManyRequests, message)
 }
+
+// accountLockedResponse responde 429 indicando cuándo se puede reintentar.
+func (app *application) accountLockedResponse(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", fmt.Sprintf("%d", int(retryAfter.Seconds())+1))
+	app.writeJSONError(w, http.StatusTooManyRequests, "demasiados intentos fallidos, vuelve a intentarlo más tarde")
+}
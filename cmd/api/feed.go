@@ -10,13 +10,20 @@ func (app *application) getUserFeedHandler(w http.ResponseWriter, r *http.Reques
 	// 1. Obtenemos al usuario logueado desde el contexto.
 	user := r.Context().Value(userCtxKey).(*store.User)
 
-	// 2. Llamamos a nuestra nueva función del store.
-	feed, err := app.store.Posts.GetUserFeed(r.Context(), user.ID)
+	// 2. Leemos la paginación y los filtros de la URL.
+	query, err := app.readPaginatedQuery(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// 3. Llamamos a nuestra función del store.
+	feed, nextCursor, err := app.store.Posts.GetUserFeed(r.Context(), user.ID, query)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	// 3. Devolvemos el feed como JSON.
-	app.jsonResponse(w, http.StatusOK, feed)
+	// 4. Devolvemos el feed junto al cursor de la siguiente página.
+	app.paginatedResponse(w, http.StatusOK, feed, nextCursor)
 }
@@ -31,6 +31,16 @@ func (app *application) jsonResponse(w http.ResponseWriter, status int, data any
 	return writeJSON(w, status, envelope{Data: data})
 }
 
+// paginatedResponse es como jsonResponse pero añade el cursor de la siguiente página.
+// Si next_cursor no aparece, el cliente llegó al final del listado.
+func (app *application) paginatedResponse(w http.ResponseWriter, status int, data any, nextCursor string) error {
+	type envelope struct {
+		Data       any    `json:"data"`
+		NextCursor string `json:"next_cursor,omitempty"`
+	}
+	return writeJSON(w, status, envelope{Data: data, NextCursor: nextCursor})
+}
+
 func writeJSON(w http.ResponseWriter, status int, data any) error {
 	js, err := json.Marshal(data)
 	if err != nil {
@@ -0,0 +1,42 @@
+// cmd/api/jwks.go
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"GopherSocial/internal/auth"
+)
+
+// loadSigningKeys registra en el authenticator las claves de AUTH_SIGNING_KEYS,
+// con el formato "kid1=/ruta/clave1.pem,kid2=/ruta/clave2.pem".
+func loadSigningKeys(authenticator *auth.JWTAuthenticator, spec string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kid, path, ok := strings.Cut(entry, "=")
+		if !ok || kid == "" || path == "" {
+			return fmt.Errorf("entrada inválida en AUTH_SIGNING_KEYS: %q", entry)
+		}
+
+		key, err := auth.LoadPEMKey(kid, path)
+		if err != nil {
+			return err
+		}
+		authenticator.AddKey(key)
+	}
+	return nil
+}
+
+// jwksHandler publica las claves públicas con las que se pueden verificar nuestros tokens.
+// No usa el sobre {"data": ...} porque los clientes esperan el formato estándar JWKS.
+func (app *application) jwksHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := writeJSON(w, http.StatusOK, app.authenticator.JWKS()); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,118 @@
+// cmd/api/login_protection.go
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"GopherSocial/internal/store"
+)
+
+// clientIP devuelve la IP del cliente sin el puerto.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func accountLoginKey(email string) string {
+	return "account:" + strings.ToLower(email)
+}
+
+func ipLoginKey(ip string) string {
+	return "ip:" + ip
+}
+
+// loginLockedFor devuelve cuánto falta para poder volver a intentar el login
+// con ese email o desde esa IP (0 si no hay bloqueo).
+func (app *application) loginLockedFor(ctx context.Context, email, ip string) (time.Duration, error) {
+	accountLock, err := app.cacheStorage.LoginAttempts.LockedFor(ctx, accountLoginKey(email))
+	if err != nil {
+		return 0, err
+	}
+	ipLock, err := app.cacheStorage.LoginAttempts.LockedFor(ctx, ipLoginKey(ip))
+	if err != nil {
+		return 0, err
+	}
+	return max(accountLock, ipLock), nil
+}
+
+// registerLoginFailure cuenta un intento fallido por cuenta y por IP, aplica el
+// bloqueo que corresponda y deja el intento en la auditoría. user puede ser nil
+// si el email no corresponde a nadie. Los errores solo se registran en el log:
+// nunca deben cambiar la respuesta del login.
+func (app *application) registerLoginFailure(ctx context.Context, email, ip string, user *store.User, reason string) {
+	cfg := app.config.loginProtection
+
+	app.auditLoginFailure(ctx, email, ip, user, reason)
+
+	failures, err := app.cacheStorage.LoginAttempts.RegisterFailure(ctx, accountLoginKey(email), cfg.window)
+	if err != nil {
+		app.logger.Printf("ERROR: no se pudo contar el login fallido: %s", err)
+	} else if lockout := cfg.lockoutFor(failures, cfg.maxAttempts); lockout > 0 {
+		if err := app.cacheStorage.LoginAttempts.Lock(ctx, accountLoginKey(email), lockout); err != nil {
+			app.logger.Printf("ERROR: no se pudo bloquear la cuenta: %s", err)
+		}
+		// Avisamos al dueño solo la primera vez que se bloquea la cuenta.
+		if user != nil && failures == int64(cfg.maxAttempts) {
+			go app.sendAccountLockedEmail(user, ip, lockout)
+		}
+	}
+
+	failures, err = app.cacheStorage.LoginAttempts.RegisterFailure(ctx, ipLoginKey(ip), cfg.window)
+	if err != nil {
+		app.logger.Printf("ERROR: no se pudo contar el login fallido: %s", err)
+	} else if lockout := cfg.lockoutFor(failures, cfg.ipMaxAttempts); lockout > 0 {
+		if err := app.cacheStorage.LoginAttempts.Lock(ctx, ipLoginKey(ip), lockout); err != nil {
+			app.logger.Printf("ERROR: no se pudo bloquear la IP: %s", err)
+		}
+	}
+}
+
+// auditLoginFailure deja constancia del intento fallido sin tocar los contadores.
+func (app *application) auditLoginFailure(ctx context.Context, email, ip string, user *store.User, reason string) {
+	attempt := &store.LoginAttempt{Email: email, IP: ip, Reason: reason}
+	if user != nil {
+		attempt.UserID = &user.ID
+	}
+	if err := app.store.LoginAttempts.Create(ctx, attempt); err != nil {
+		app.logger.Printf("ERROR: no se pudo auditar el login fallido: %s", err)
+	}
+}
+
+// resetLoginFailures olvida los fallos de la cuenta tras un login correcto.
+// Los de la IP se mantienen: un atacante con una cuenta propia no debe poder limpiarlos.
+func (app *application) resetLoginFailures(ctx context.Context, email string) {
+	if err := app.cacheStorage.LoginAttempts.Reset(ctx, accountLoginKey(email)); err != nil {
+		app.logger.Printf("ERROR: no se pudo reiniciar el contador de logins fallidos: %s", err)
+	}
+}
+
+// lockoutFor calcula el bloqueo tras failures fallos: nada hasta llegar a threshold y
+// después un backoff exponencial (base, 2*base, 4*base...) con tope.
+func (cfg loginProtectionConfig) lockoutFor(failures int64, threshold int) time.Duration {
+	if failures < int64(threshold) {
+		return 0
+	}
+
+	exp := min(failures-int64(threshold), 20) // Evitamos desbordar el desplazamiento
+	return min(cfg.baseLockout<<exp, cfg.maxLockout)
+}
+
+func (app *application) sendAccountLockedEmail(user *store.User, ip string, lockout time.Duration) {
+	data := map[string]string{
+		"Username":          user.Username,
+		"IP":                ip,
+		"LockedFor":         lockout.String(),
+		"ForgotPasswordURL": app.forgotPasswordURL(),
+	}
+
+	if _, err := app.mailer.Send("account_locked.tmpl", user.Username, user.Email, data); err != nil {
+		app.logger.Printf("ERROR: no se pudo enviar el aviso de bloqueo: %s", err)
+	}
+}
@@ -3,8 +3,10 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -19,6 +21,10 @@ type userKey string
 
 const userCtxKey userKey = "user"
 
+type claimsKey string
+
+const claimsCtxKey claimsKey = "claims"
+
 func (app *application) AuthTokenMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
@@ -34,6 +40,13 @@ func (app *application) AuthTokenMiddleware(next http.Handler) http.Handler {
 		}
 
 		tokenString := parts[1]
+
+		// Los tokens personales no son JWT: tienen su propio camino.
+		if strings.HasPrefix(tokenString, personalTokenPrefix) {
+			app.authenticatePersonalToken(w, r, next, tokenString)
+			return
+		}
+
 		token, err := app.authenticator.ValidateToken(tokenString)
 		if err != nil || !token.Valid {
 			app.unauthorizedErrorResponse(w, r)
@@ -46,6 +59,32 @@ func (app *application) AuthTokenMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		// Rechazamos los tokens revocados (por ejemplo, tras un logout).
+		if jti, _ := claims["jti"].(string); jti != "" {
+			revoked, err := app.cacheStorage.Tokens.IsRevoked(r.Context(), jti)
+			if err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+			if revoked {
+				app.unauthorizedErrorResponse(w, r)
+				return
+			}
+		}
+
+		// Rechazamos los tokens de una sesión cerrada (ej. desde otro dispositivo).
+		if sid, _ := claims["sid"].(string); sid != "" {
+			revoked, err := app.cacheStorage.Tokens.IsSessionRevoked(r.Context(), sid)
+			if err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+			if revoked {
+				app.unauthorizedErrorResponse(w, r)
+				return
+			}
+		}
+
 		// Extraemos el ID de usuario del token
 		userIDStr, err := claims.GetSubject()
 		if err != nil {
@@ -55,41 +94,154 @@ func (app *application) AuthTokenMiddleware(next http.Handler) http.Handler {
 
 		userID, _ := strconv.ParseInt(userIDStr, 10, 64)
 
-		// --- LÓGICA DE CACHING ---
-		// 1. Intentamos obtener el usuario de la caché.
-		user, err := app.cacheStorage.Users.Get(r.Context(), userID)
+		user, err := app.getUser(r.Context(), userID)
 		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				app.unauthorizedErrorResponse(w, r)
+				return
+			}
 			app.internalServerError(w, r, err)
 			return
 		}
 
-		// 2. Si no está en la caché (cache miss), lo buscamos en la DB.
-		if user == nil {
-			user, err = app.store.Users.GetByID(r.Context(), userID)
-			if err != nil {
-				app.unauthorizedErrorResponse(w, r)
-				return
-			}
-			// 3. Y lo guardamos en la caché para la próxima vez.
-			if err := app.cacheStorage.Users.Set(r.Context(), user); err != nil {
-				app.internalServerError(w, r, err)
-				return
-			}
+		// Un usuario desactivado o borrado no puede seguir usando tokens emitidos antes.
+		if !user.IsActive || user.DeletedAt != nil {
+			app.unauthorizedErrorResponse(w, r)
+			return
+		}
+		if user.IsBanned() {
+			app.bannedResponse(w, r, user)
+			return
 		}
 
-		// TODO: Buscar el usuario en la BD y añadirlo al contexto.
-		// Por ahora, solo pasamos el ID para mantenerlo simple.
 		ctx := context.WithValue(r.Context(), userCtxKey, user)
+		ctx = context.WithValue(ctx, claimsCtxKey, claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// require2FAMiddleware bloquea a los usuarios cuyo rol exige 2FA y aún no lo activaron.
+// Las rutas de inscripción en 2FA no deben pasar por aquí.
+func (app *application) require2FAMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(userCtxKey).(*store.User)
+
+		if user.Role.Require2FA && !user.TwoFactorEnabled {
+			app.twoFactorRequiredResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// getUser obtiene el usuario de la caché o, si no está, de la DB (y lo cachea).
+func (app *application) getUser(ctx context.Context, userID int64) (*store.User, error) {
+	// --- LÓGICA DE CACHING ---
+	// 1. Intentamos obtener el usuario de la caché.
+	user, err := app.cacheStorage.Users.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Si no está en la caché (cache miss), lo buscamos en la DB.
+	if user == nil {
+		user, err = app.store.Users.GetByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		// 3. Y lo guardamos en la caché para la próxima vez.
+		if err := app.cacheStorage.Users.Set(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// authenticatePersonalToken es la parte de AuthTokenMiddleware para tokens personales.
+// Además del usuario, deja en el contexto los scopes del token.
+func (app *application) authenticatePersonalToken(w http.ResponseWriter, r *http.Request, next http.Handler, tokenString string) {
+	hash := sha256.Sum256([]byte(tokenString))
+
+	token, err := app.store.AccessTokens.GetByHash(r.Context(), hash[:])
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.unauthorizedErrorResponse(w, r)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	user, err := app.getUser(r.Context(), token.UserID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.unauthorizedErrorResponse(w, r)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !user.IsActive || user.DeletedAt != nil {
+		app.unauthorizedErrorResponse(w, r)
+		return
+	}
+	if user.IsBanned() {
+		app.bannedResponse(w, r, user)
+		return
+	}
+
+	if err := app.store.AccessTokens.Touch(r.Context(), token.ID); err != nil {
+		app.logger.Printf("ERROR: no se pudo actualizar el último uso del token: %s", err)
+	}
+
+	ctx := context.WithValue(r.Context(), userCtxKey, user)
+	ctx = context.WithValue(ctx, scopesCtxKey, token.Scopes)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+type scopesKey string
+
+// scopesCtxKey solo existe en peticiones autenticadas con un token personal.
+// Un JWT de sesión no tiene restricciones de scope.
+const scopesCtxKey scopesKey = "scopes"
+
+// requireScope exige que un token personal incluya el scope dado.
+func (app *application) requireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scopes, isPersonalToken := r.Context().Value(scopesCtxKey).([]string)
+			if isPersonalToken && !slices.Contains(scopes, scope) {
+				app.forbiddenResponse(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// denyPersonalTokens reserva una ruta a sesiones reales (ej. gestionar los propios
+// tokens o la 2FA), para que un token filtrado no pueda ampliar su alcance.
+func (app *application) denyPersonalTokens(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, isPersonalToken := r.Context().Value(scopesCtxKey).([]string); isPersonalToken {
+			app.forbiddenResponse(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 type postKey string
 
 const postCtxKey postKey = "post"
 
 // postsContextMiddleware carga un post basado en el postID de la URL
-// y lo guarda en el contexto de la petición.
+// y lo guarda en el contexto de la petición. Los posts de una cuenta privada
+// solo existen para sus seguidores aprobados, y los de alguien con quien hay
+// un bloqueo no existen para nadie de los dos (salvo para los moderadores).
+// Así lo cumplen todas las rutas bajo /v1/posts/{postID}.
 func (app *application) postsContextMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		id, err := strconv.ParseInt(chi.URLParam(r, "postID"), 10, 64)
@@ -108,25 +260,116 @@ func (app *application) postsContextMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		user := r.Context().Value(userCtxKey).(*store.User)
+		if !user.Role.HasPermission(permPostUpdateAny) && !user.Role.HasPermission(permPostDeleteAny) {
+			visible, err := app.store.Followers.CanSeePosts(r.Context(), post.UserID, user.ID)
+			if err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+			if !visible {
+				app.notFoundResponse(w, r)
+				return
+			}
+
+			blocked, err := app.store.Blocks.IsBlocked(r.Context(), user.ID, post.UserID)
+			if err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+			if blocked {
+				app.notFoundResponse(w, r)
+				return
+			}
+		}
+
 		ctx := context.WithValue(r.Context(), postCtxKey, post)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-func (app *application) checkPermission(requiredLevel int) func(http.Handler) http.Handler {
+type commentKey string
+
+const commentCtxKey commentKey = "comment"
+
+// commentsContextMiddleware carga el comentario {commentID} del post que ya cargó
+// postsContextMiddleware y lo guarda en el contexto. Si hay un bloqueo entre su
+// autor y quien consulta, el comentario no existe para él (salvo moderadores).
+func (app *application) commentsContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "commentID"), 10, 64)
+		if err != nil {
+			app.notFoundResponse(w, r)
+			return
+		}
+
+		post := r.Context().Value(postCtxKey).(*store.Post)
+		comment, err := app.store.Comments.GetByID(r.Context(), post.ID, id)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				app.notFoundResponse(w, r)
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		user := r.Context().Value(userCtxKey).(*store.User)
+		if !user.Role.HasPermission(permCommentUpdateAny) && !user.Role.HasPermission(permCommentDeleteAny) {
+			blocked, err := app.store.Blocks.IsBlocked(r.Context(), user.ID, comment.UserID)
+			if err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+			if blocked {
+				app.notFoundResponse(w, r)
+				return
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), commentCtxKey, comment)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ownerResolver devuelve el dueño del recurso de la petición (ya cargado en el
+// contexto por su middleware) y false si no lo encuentra.
+type ownerResolver func(r *http.Request) (int64, bool)
+
+// postOwner resuelve el autor del post cargado por postsContextMiddleware.
+func postOwner(r *http.Request) (int64, bool) {
+	post, ok := r.Context().Value(postCtxKey).(*store.Post)
+	if !ok {
+		return 0, false
+	}
+	return post.UserID, true
+}
+
+// commentOwner resuelve el autor del comentario cargado por commentsContextMiddleware.
+func commentOwner(r *http.Request) (int64, bool) {
+	comment, ok := r.Context().Value(commentCtxKey).(*store.Comment)
+	if !ok {
+		return 0, false
+	}
+	return comment.UserID, true
+}
+
+// requirePermission deja pasar a quien tenga el permiso en su rol. Si se indica
+// owner, el dueño del recurso también pasa aunque no tenga el permiso.
+func (app *application) requirePermission(permission string, owner ownerResolver) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			user := r.Context().Value(userCtxKey).(*store.User)
-			post := r.Context().Value(postCtxKey).(*store.Post) // Asumimos que el post está en el contexto
 
-			// Un usuario siempre puede modificar sus propios posts
-			if post.UserID == user.ID {
-				next.ServeHTTP(w, r)
-				return
+			// Un usuario siempre puede gestionar sus propios recursos
+			if owner != nil {
+				if ownerID, ok := owner(r); ok && ownerID == user.ID {
+					next.ServeHTTP(w, r)
+					return
+				}
 			}
 
-			// Si no es su post, verificamos si su nivel es suficiente
-			if user.Role.Level >= requiredLevel {
+			if user.Role.HasPermission(permission) {
 				next.ServeHTTP(w, r)
 				return
 			}
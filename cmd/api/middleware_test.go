@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"GopherSocial/internal/store"
+)
+
+func TestAuthTokenMiddleware(t *testing.T) {
+	app := newTestApplication(t)
+	deletedAt := time.Now()
+	bannedAt := time.Now()
+
+	users := []*store.User{
+		{ID: 1, Username: "activo", IsActive: true},
+		{ID: 2, Username: "desactivado", IsActive: false},
+		{ID: 3, Username: "borrado", IsActive: true, DeletedAt: &deletedAt},
+		{ID: 4, Username: "suspendido", IsActive: true, BannedAt: &bannedAt},
+	}
+	for _, u := range users {
+		app.cacheStorage.Users.Set(t.Context(), u)
+	}
+
+	handler := app.AuthTokenMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		userID int64
+		header func(token string) string
+		want   int
+	}{
+		{name: "active user", userID: 1, want: http.StatusOK},
+		{name: "missing header", userID: 1, header: func(string) string { return "" }, want: http.StatusUnauthorized},
+		{name: "not a bearer token", userID: 1, header: func(token string) string { return "Basic " + token }, want: http.StatusUnauthorized},
+		{name: "garbage token", userID: 1, header: func(string) string { return "Bearer no-es-un-jwt" }, want: http.StatusUnauthorized},
+		{name: "deactivated user", userID: 2, want: http.StatusUnauthorized},
+		{name: "deleted user", userID: 3, want: http.StatusUnauthorized},
+		{name: "banned user", userID: 4, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := app.generateAccessToken(&store.User{ID: tt.userID}, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			header := "Bearer " + token
+			if tt.header != nil {
+				header = tt.header(token)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if header != "" {
+				req.Header.Set("Authorization", header)
+			}
+
+			rr := executeRequest(req, handler)
+			checkResponseCode(t, tt.want, rr.Code)
+		})
+	}
+}
+
+func TestAuthTokenMiddlewareSessionRevocation(t *testing.T) {
+	app := newTestApplication(t)
+	user := &store.User{ID: 1, IsActive: true}
+	app.cacheStorage.Users.Set(t.Context(), user)
+
+	handler := app.AuthTokenMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return executeRequest(req, handler).Code
+	}
+
+	token := func(sessionID string) string {
+		t.Helper()
+		token, err := app.generateAccessToken(user, sessionID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	laptop, phone := token("session-laptop"), token("session-phone")
+
+	// "Cerrar sesión en todas partes" devuelve las sesiones cerradas.
+	if err := app.revokeSessionTokens(t.Context(), []string{"session-laptop", "session-phone"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Un login posterior abre una sesión nueva, que no está revocada.
+	newLogin := token("session-new")
+
+	checkResponseCode(t, http.StatusUnauthorized, request(laptop))
+	checkResponseCode(t, http.StatusUnauthorized, request(phone))
+	checkResponseCode(t, http.StatusOK, request(newLogin))
+}
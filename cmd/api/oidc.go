@@ -0,0 +1,226 @@
+// cmd/api/oidc.go
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"GopherSocial/internal/auth"
+	"GopherSocial/internal/store"
+	"GopherSocial/internal/store/cache"
+)
+
+const oidcStateExp = time.Minute * 10 // Tiempo máximo para volver del proveedor
+
+var errOIDCEmailNotVerified = errors.New("el proveedor no confirmó que el email esté verificado")
+
+// oidcLoginHandler inicia el login externo: guarda state, nonce y el verificador
+// PKCE en Redis y redirige al usuario al proveedor.
+func (app *application) oidcLoginHandler(w http.ResponseWriter, r *http.Request) {
+	state, err := auth.RandomString(32)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	nonce, err := auth.RandomString(32)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	verifier, challenge, err := auth.NewPKCE()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	data := &cache.OIDCState{Nonce: nonce, CodeVerifier: verifier}
+	if err := app.cacheStorage.OIDCStates.Save(r.Context(), state, data, oidcStateExp); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, app.oidc.AuthCodeURL(state, nonce, challenge), http.StatusFound)
+}
+
+// oidcCallbackHandler recibe al usuario de vuelta del proveedor, canjea el código
+// y termina el login con nuestros propios tokens.
+func (app *application) oidcCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+
+	if providerErr := qs.Get("error"); providerErr != "" {
+		app.badRequestResponse(w, r, errors.New("el proveedor rechazó el login: "+providerErr))
+		return
+	}
+
+	state, code := qs.Get("state"), qs.Get("code")
+	if state == "" || code == "" {
+		app.badRequestResponse(w, r, errors.New("faltan los parámetros state y code"))
+		return
+	}
+
+	data, err := app.cacheStorage.OIDCStates.Pop(r.Context(), state)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if data == nil {
+		app.badRequestResponse(w, r, errors.New("state inválido o caducado"))
+		return
+	}
+
+	identity, err := app.oidc.Exchange(r.Context(), code, data.CodeVerifier, data.Nonce)
+	if err != nil {
+		app.logger.Printf("WARN: login OIDC rechazado: %s", err)
+		app.unauthorizedErrorResponse(w, r)
+		return
+	}
+
+	user, err := app.userFromOIDCIdentity(r.Context(), identity)
+	if err != nil {
+		switch err {
+		case errOIDCEmailNotVerified:
+			app.writeJSONError(w, http.StatusForbidden, err.Error())
+		case store.ErrDuplicateEmail:
+			app.conflictResponse(w, r, err)
+		case store.ErrConflict:
+			app.conflictResponse(w, r, errors.New("esta cuenta externa ya está vinculada a otro usuario"))
+		case store.ErrNotFound:
+			app.unauthorizedErrorResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.completeLogin(w, r, user)
+}
+
+// userFromOIDCIdentity busca al usuario vinculado a la identidad externa. Si no
+// hay vínculo, lo enlaza con la cuenta que tenga el mismo email verificado (y la
+// activa si aún estaba pendiente) o, si no existe, crea una cuenta nueva ya activada.
+func (app *application) userFromOIDCIdentity(ctx context.Context, identity *auth.OIDCIdentity) (*store.User, error) {
+	provider := app.config.oidc.provider
+
+	user, err := app.linkedOIDCUser(ctx, provider, identity.Subject)
+	if err != store.ErrNotFound {
+		return user, err
+	}
+	// Seguimos: es la primera vez que vemos esta identidad.
+
+	// Sin un email verificado por el proveedor no podemos vincular ni crear cuentas.
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, errOIDCEmailNotVerified
+	}
+
+	link := &store.Identity{Provider: provider, Subject: identity.Subject, Email: identity.Email}
+
+	user, err = app.store.Users.GetByEmail(ctx, identity.Email)
+	switch err {
+	case nil:
+		link.UserID = user.ID
+		err = app.store.Identities.Link(ctx, link)
+	case store.ErrNotFound:
+		// Quizá se registró con email y contraseña pero nunca activó la cuenta.
+		err = app.activateOIDCUser(ctx, identity, link)
+		if err == store.ErrNotFound {
+			return app.createOIDCUser(ctx, identity, link)
+		}
+	}
+
+	switch err {
+	case nil:
+		return app.store.Users.GetByID(ctx, link.UserID)
+	case store.ErrConflict:
+		// Otro login con la misma identidad la vinculó a la vez: usamos ese vínculo.
+		user, err := app.linkedOIDCUser(ctx, provider, identity.Subject)
+		if err == store.ErrNotFound {
+			return nil, store.ErrConflict
+		}
+		return user, err
+	default:
+		return nil, err
+	}
+}
+
+// linkedOIDCUser devuelve el usuario ya vinculado a (provider, subject), o
+// ErrNotFound si la identidad no está vinculada o la cuenta no está activa.
+func (app *application) linkedOIDCUser(ctx context.Context, provider, subject string) (*store.User, error) {
+	userID, err := app.store.Identities.GetUserID(ctx, provider, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := app.store.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, store.ErrNotFound
+	}
+	return user, nil
+}
+
+// activateOIDCUser activa y vincula la cuenta pendiente con el email verificado
+// por el proveedor. La contraseña pasa a ser aleatoria, como en createOIDCUser:
+// quien registró la cuenta pudo no ser el dueño del email.
+func (app *application) activateOIDCUser(ctx context.Context, identity *auth.OIDCIdentity, link *store.Identity) error {
+	randomPassword, err := auth.RandomString(32)
+	if err != nil {
+		return err
+	}
+	return app.store.Users.ActivateWithIdentity(ctx, identity.Email, randomPassword, link)
+}
+
+// createOIDCUser crea la cuenta de un usuario que entra por primera vez con el proveedor.
+// La contraseña es aleatoria: si quiere usar email y contraseña, puede restablecerla.
+func (app *application) createOIDCUser(ctx context.Context, identity *auth.OIDCIdentity, link *store.Identity) (*store.User, error) {
+	randomPassword, err := auth.RandomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	// Probamos unos pocos sufijos por si el nombre de usuario ya está ocupado.
+	for range 3 {
+		username, err := usernameFromEmail(identity.Email)
+		if err != nil {
+			return nil, err
+		}
+
+		user := &store.User{Username: username, Email: identity.Email}
+		if err := user.Password.Set(randomPassword); err != nil {
+			return nil, err
+		}
+
+		err = app.store.Users.CreateWithIdentity(ctx, user, link)
+		if err == store.ErrDuplicateUsername {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Volvemos a leerlo para tener el rol completo.
+		return app.store.Users.GetByID(ctx, user.ID)
+	}
+
+	return nil, store.ErrDuplicateUsername
+}
+
+// usernameFromEmail propone un nombre de usuario a partir del email más un sufijo aleatorio.
+func usernameFromEmail(email string) (string, error) {
+	local, _, _ := strings.Cut(email, "@")
+	if len(local) > 80 {
+		local = local[:80]
+	}
+
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return local + "-" + hex.EncodeToString(suffix), nil
+}
@@ -0,0 +1,67 @@
+// cmd/api/pagination.go
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"GopherSocial/internal/store"
+)
+
+// readPaginatedQuery lee los parámetros de paginación comunes de la URL
+// (?limit=&sort=&cursor=&since=&until=&search=) y los valida.
+// Cualquier handler que devuelva un listado puede reutilizarlo.
+func (app *application) readPaginatedQuery(r *http.Request) (store.PaginatedQuery, error) {
+	q := store.PaginatedQuery{
+		Limit: 20,
+		Sort:  "desc",
+	}
+
+	qs := r.URL.Query()
+
+	if limit := qs.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return q, errors.New("limit debe ser un número")
+		}
+		q.Limit = n
+	}
+
+	if sort := qs.Get("sort"); sort != "" {
+		q.Sort = sort
+	}
+
+	if cursor := qs.Get("cursor"); cursor != "" {
+		c, err := store.DecodeCursor(cursor)
+		if err != nil {
+			return q, err
+		}
+		q.Cursor = c
+	}
+
+	if since := qs.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return q, errors.New("since debe tener formato RFC3339")
+		}
+		q.Since = &t
+	}
+
+	if until := qs.Get("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return q, errors.New("until debe tener formato RFC3339")
+		}
+		q.Until = &t
+	}
+
+	q.Search = qs.Get("search")
+
+	if err := Validate.Struct(q); err != nil {
+		return q, err
+	}
+
+	return q, nil
+}
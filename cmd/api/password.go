@@ -0,0 +1,105 @@
+// cmd/api/password.go
+package main
+
+import (
+	"crypto/sha256"
+	"net/http"
+
+	"GopherSocial/internal/store"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type ForgotPasswordPayload struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+// forgotPasswordHandler envía un enlace para restablecer la contraseña.
+// Siempre responde lo mismo para no revelar si el email está registrado.
+func (app *application) forgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var payload ForgotPasswordPayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	response := map[string]string{"message": "si el email está registrado, recibirás un enlace para restablecer tu contraseña"}
+
+	user, err := app.store.Users.GetByEmail(r.Context(), payload.Email)
+	if err != nil {
+		if err != store.ErrNotFound {
+			app.logger.Printf("ERROR: no se pudo buscar el usuario para restablecer la contraseña: %s", err)
+		}
+		app.jsonResponse(w, http.StatusAccepted, response)
+		return
+	}
+
+	plainToken := uuid.New().String()
+	hash := sha256.Sum256([]byte(plainToken))
+
+	if err := app.store.Users.CreatePasswordReset(r.Context(), user.ID, hash[:], app.config.auth.passwordResetExp); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	go func() {
+		data := map[string]string{
+			"ResetURL":  app.passwordResetURL(plainToken),
+			"Username":  user.Username,
+			"ExpiresIn": app.config.auth.passwordResetExp.String(),
+		}
+
+		if _, err := app.mailer.Send("password_reset.tmpl", user.Username, user.Email, data); err != nil {
+			app.logger.Printf("ERROR: no se pudo enviar el correo de restablecimiento: %s", err)
+		}
+	}()
+
+	app.jsonResponse(w, http.StatusAccepted, response)
+}
+
+type ResetPasswordPayload struct {
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+// resetPasswordHandler fija la nueva contraseña a partir del token del correo
+// e invalida todas las sesiones existentes del usuario.
+func (app *application) resetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var payload ResetPasswordPayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	hash := sha256.Sum256([]byte(chi.URLParam(r, "token")))
+
+	user, revoked, err := app.store.Users.ResetPassword(r.Context(), hash[:], payload.Password)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	// Los refresh tokens ya se revocaron en la transacción; aquí cortamos los access tokens.
+	if err := app.revokeSessionTokens(r.Context(), revoked); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.cacheStorage.Users.Delete(r.Context(), user.ID)
+
+	app.jsonResponse(w, http.StatusOK, map[string]string{"message": "¡Contraseña actualizada! Vuelve a iniciar sesión."})
+}
@@ -11,9 +11,12 @@ import (
 // getPostHandler maneja la recuperación de una publicación.
 func (app *application) getPostHandler(w http.ResponseWriter, r *http.Request) {
 	post := r.Context().Value(postCtxKey).(*store.Post)
+	viewer := r.Context().Value(userCtxKey).(*store.User)
 
 	// --- AÑADE ESTA LÓGICA ---
-	comments, err := app.store.Comments.GetByPostID(r.Context(), post.ID)
+	// Solo la primera página de comentarios de primer nivel; el resto y las
+	// respuestas se piden a /v1/posts/{postID}/comments.
+	comments, _, err := app.store.Comments.GetByPostID(r.Context(), post.ID, viewer.ID, store.PaginatedQuery{Limit: 20, Sort: "desc"})
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -21,6 +24,12 @@ func (app *application) getPostHandler(w http.ResponseWriter, r *http.Request) {
 	post.Comments = comments // Adjuntamos los comentarios al post
 	// -------------------------
 
+	post.Reactions, err = app.store.Reactions.GetPostSummary(r.Context(), post.ID, viewer.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
 	app.jsonResponse(w, http.StatusOK, post)
 }
 
@@ -0,0 +1,222 @@
+// cmd/api/profile.go
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"GopherSocial/internal/store"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// userProfile es la vista pública de un usuario: sin email, rol ni estado de la
+// cuenta, pero con sus contadores de seguidores.
+type userProfile struct {
+	ID          int64  `json:"id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	AvatarURL   string `json:"avatar_url"`
+	IsPrivate   bool   `json:"is_private"`
+	CreatedAt   string `json:"created_at"`
+	store.FollowStats
+}
+
+func newUserProfile(user *store.User, stats *store.FollowStats) userProfile {
+	return userProfile{
+		ID:          user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarURL,
+		IsPrivate:   user.IsPrivate,
+		CreatedAt:   user.CreatedAt,
+		FollowStats: *stats,
+	}
+}
+
+type UpdateProfilePayload struct {
+	Username    *string `json:"username" validate:"omitempty,min=1,max=100"`
+	DisplayName *string `json:"display_name" validate:"omitempty,max=100"`
+	Bio         *string `json:"bio" validate:"omitempty,max=500"`
+	AvatarURL   *string `json:"avatar_url" validate:"omitempty,url,max=2048"`
+	IsPrivate   *bool   `json:"is_private"`
+}
+
+type ChangePasswordPayload struct {
+	CurrentPassword string `json:"current_password" validate:"required,max=72"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+// readVisibleUser carga el usuario del userID de la URL. Las cuentas sin
+// activar, borradas o suspendidas no tienen perfil público: responde 404.
+func (app *application) readVisibleUser(w http.ResponseWriter, r *http.Request) (*store.User, bool) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	user, err := app.getUser(r.Context(), userID)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return nil, false
+	}
+
+	if !user.IsActive || user.DeletedAt != nil || user.IsBanned() {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	return user, true
+}
+
+// getUserProfileHandler devuelve el perfil público de cualquier usuario activo.
+func (app *application) getUserProfileHandler(w http.ResponseWriter, r *http.Request) {
+	viewer := r.Context().Value(userCtxKey).(*store.User)
+
+	user, ok := app.readVisibleUser(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := app.store.Followers.GetStats(r.Context(), user.ID, viewer.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, newUserProfile(user, stats))
+}
+
+// getCurrentUserHandler devuelve la cuenta completa del usuario autenticado.
+func (app *application) getCurrentUserHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+	app.jsonResponse(w, http.StatusOK, user)
+}
+
+// updateProfileHandler actualiza solo los campos del perfil que se enviaron.
+func (app *application) updateProfileHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	var payload UpdateProfilePayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if payload.Username != nil {
+		user.Username = *payload.Username
+	}
+	if payload.DisplayName != nil {
+		user.DisplayName = *payload.DisplayName
+	}
+	if payload.Bio != nil {
+		user.Bio = *payload.Bio
+	}
+	if payload.AvatarURL != nil {
+		user.AvatarURL = *payload.AvatarURL
+	}
+	if payload.IsPrivate != nil {
+		user.IsPrivate = *payload.IsPrivate
+	}
+
+	if err := app.store.Users.UpdateProfile(r.Context(), user); err != nil {
+		switch err {
+		case store.ErrDuplicateUsername:
+			app.conflictResponse(w, r, err)
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.cacheStorage.Users.Delete(r.Context(), user.ID)
+
+	app.jsonResponse(w, http.StatusOK, user)
+}
+
+// changePasswordHandler cambia la contraseña tras comprobar la actual y cierra
+// las demás sesiones del usuario.
+func (app *application) changePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	ctxUser := r.Context().Value(userCtxKey).(*store.User)
+
+	var payload ChangePasswordPayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// El usuario de la caché no trae el hash de la contraseña: vamos a la DB.
+	user, err := app.store.Users.GetByID(r.Context(), ctxUser.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := user.Password.Compare(payload.CurrentPassword); err != nil {
+		app.badRequestResponse(w, r, errors.New("la contraseña actual no es correcta"))
+		return
+	}
+
+	revoked, err := app.store.Users.ChangePassword(r.Context(), user, payload.NewPassword, currentSessionID(r))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.revokeSessionTokens(r.Context(), revoked); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.cacheStorage.Users.Delete(r.Context(), user.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteAccountHandler borra la cuenta del usuario. Durante el periodo de
+// gracia (DELETED_USER_GRACE) basta con volver a iniciar sesión para recuperarla.
+func (app *application) deleteAccountHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	revoked, err := app.store.Users.SoftDelete(r.Context(), user.ID)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	// Todos sus access tokens dejan de valer ya.
+	if err := app.revokeSessionTokens(r.Context(), revoked); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.cacheStorage.Users.Delete(r.Context(), user.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,112 @@
+// cmd/api/reactions.go
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"GopherSocial/internal/store"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// readReactionKind lee {kind} de la URL y comprueba que sea un tipo permitido.
+func readReactionKind(r *http.Request) (string, error) {
+	kind := chi.URLParam(r, "kind")
+	if !store.IsReactionKind(kind) {
+		return "", errors.New("tipo de reacción no válido, usa uno de: " + strings.Join(store.ReactionKinds, ", "))
+	}
+	return kind, nil
+}
+
+// reactionErrorResponse traduce los errores del store de reacciones.
+func (app *application) reactionErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case store.ErrUnknownReaction:
+		app.badRequestResponse(w, r, err)
+	case store.ErrNotFound:
+		app.notFoundResponse(w, r)
+	default:
+		app.internalServerError(w, r, err)
+	}
+}
+
+// reactToPostHandler deja o cambia la reacción del usuario en la publicación.
+// Es idempotente: repetir la misma reacción no cambia nada.
+func (app *application) reactToPostHandler(w http.ResponseWriter, r *http.Request) {
+	post := r.Context().Value(postCtxKey).(*store.Post)
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	kind, err := readReactionKind(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Reactions.ReactToPost(r.Context(), post.ID, user.ID, kind); err != nil {
+		app.reactionErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// unreactPostHandler quita la reacción del usuario en la publicación.
+func (app *application) unreactPostHandler(w http.ResponseWriter, r *http.Request) {
+	post := r.Context().Value(postCtxKey).(*store.Post)
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	kind, err := readReactionKind(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Reactions.UnreactPost(r.Context(), post.ID, user.ID, kind); err != nil {
+		app.reactionErrorResponse(w, r, err) // 404 si no había reaccionado así
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// reactToCommentHandler deja o cambia la reacción del usuario en un comentario.
+func (app *application) reactToCommentHandler(w http.ResponseWriter, r *http.Request) {
+	post := r.Context().Value(postCtxKey).(*store.Post)
+	comment := r.Context().Value(commentCtxKey).(*store.Comment)
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	kind, err := readReactionKind(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Reactions.ReactToComment(r.Context(), post.ID, comment.ID, user.ID, kind); err != nil {
+		app.reactionErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// unreactCommentHandler quita la reacción del usuario en un comentario.
+func (app *application) unreactCommentHandler(w http.ResponseWriter, r *http.Request) {
+	post := r.Context().Value(postCtxKey).(*store.Post)
+	comment := r.Context().Value(commentCtxKey).(*store.Comment)
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	kind, err := readReactionKind(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Reactions.UnreactComment(r.Context(), post.ID, comment.ID, user.ID, kind); err != nil {
+		app.reactionErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
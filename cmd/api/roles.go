@@ -0,0 +1,311 @@
+// cmd/api/roles.go
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"GopherSocial/internal/store"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Permisos con nombre que se conceden a los roles (tabla permissions).
+const (
+	permPostUpdateAny    = "post.update.any"
+	permPostDeleteAny    = "post.delete.any"
+	permCommentUpdateAny = "comment.update.any"
+	permCommentDeleteAny = "comment.delete.any"
+	permUserBan          = "user.ban"
+	permUserRoleUpdate   = "user.role.update"
+	permRoleManage       = "role.manage"
+)
+
+type CreateRolePayload struct {
+	Name       string `json:"name" validate:"required,max=255"`
+	Level      int    `json:"level" validate:"gte=0"`
+	Require2FA bool   `json:"require_2fa"`
+}
+
+type UpdateRolePayload struct {
+	Name       *string `json:"name" validate:"omitempty,max=255"`
+	Level      *int    `json:"level" validate:"omitempty,gte=0"`
+	Require2FA *bool   `json:"require_2fa"`
+}
+
+type SetRolePermissionsPayload struct {
+	Permissions []string `json:"permissions" validate:"unique,dive,required"`
+}
+
+// readRoleID lee el roleID de la URL.
+func readRoleID(r *http.Request) (int64, error) {
+	return strconv.ParseInt(chi.URLParam(r, "roleID"), 10, 64)
+}
+
+// canManageRole indica si actor puede crear, editar o borrar un rol de ese nivel:
+// como con los usuarios (outranks), solo los que están por debajo del suyo.
+func canManageRole(actor *store.User, level int) bool {
+	return level < actor.Role.Level
+}
+
+func (app *application) listRolesHandler(w http.ResponseWriter, r *http.Request) {
+	roles, err := app.store.Roles.GetAll(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, roles)
+}
+
+func (app *application) getRoleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readRoleID(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	role, err := app.store.Roles.GetByID(r.Context(), id)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, role)
+}
+
+func (app *application) createRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var payload CreateRolePayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	actor := r.Context().Value(userCtxKey).(*store.User)
+	if !canManageRole(actor, payload.Level) {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	role := &store.Role{
+		Name:       payload.Name,
+		Level:      payload.Level,
+		Require2FA: payload.Require2FA,
+	}
+
+	if err := app.store.Roles.Create(r.Context(), role); err != nil {
+		switch err {
+		case store.ErrConflict:
+			app.conflictResponse(w, r, errors.New("ya existe un rol con ese nombre"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, role)
+}
+
+// invalidateRoleUsers saca de la caché a los usuarios con el rol, que guardan
+// una copia de su nivel y sus permisos. Si no se puede, los cambios se verán al
+// expirar cada entrada (cache.UserExpTime).
+func (app *application) invalidateRoleUsers(ctx context.Context, roleID int64) {
+	ids, err := app.store.Roles.GetUserIDs(ctx, roleID)
+	if err != nil {
+		app.logger.Printf("ERROR: no se pudo invalidar la caché de los usuarios del rol %d: %s", roleID, err)
+		return
+	}
+	for _, id := range ids {
+		app.cacheStorage.Users.Delete(ctx, id)
+	}
+}
+
+func (app *application) updateRoleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readRoleID(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var payload UpdateRolePayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	role, err := app.store.Roles.GetByID(r.Context(), id)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	actor := r.Context().Value(userCtxKey).(*store.User)
+	if !canManageRole(actor, role.Level) || (payload.Level != nil && !canManageRole(actor, *payload.Level)) {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	// Actualizamos solo los campos que se enviaron
+	if payload.Name != nil {
+		role.Name = *payload.Name
+	}
+	if payload.Level != nil {
+		role.Level = *payload.Level
+	}
+	if payload.Require2FA != nil {
+		role.Require2FA = *payload.Require2FA
+	}
+
+	if err := app.store.Roles.Update(r.Context(), role); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		case store.ErrConflict:
+			app.conflictResponse(w, r, errors.New("ya existe un rol con ese nombre"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.invalidateRoleUsers(r.Context(), role.ID)
+
+	app.jsonResponse(w, http.StatusOK, role)
+}
+
+func (app *application) deleteRoleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readRoleID(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	role, err := app.store.Roles.GetByID(r.Context(), id)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	actor := r.Context().Value(userCtxKey).(*store.User)
+	if !canManageRole(actor, role.Level) {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	if err := app.store.Roles.Delete(r.Context(), id); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		case store.ErrConflict:
+			app.conflictResponse(w, r, errors.New("el rol todavía tiene usuarios asignados"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setRolePermissionsHandler reemplaza la lista completa de permisos del rol. Nadie
+// puede conceder un permiso que su propio rol no tiene.
+func (app *application) setRolePermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := readRoleID(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var payload SetRolePermissionsPayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	role, err := app.store.Roles.GetByID(r.Context(), id)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	actor := r.Context().Value(userCtxKey).(*store.User)
+	if !canManageRole(actor, role.Level) {
+		app.forbiddenResponse(w, r)
+		return
+	}
+	for _, permission := range payload.Permissions {
+		if !actor.Role.HasPermission(permission) {
+			app.forbiddenResponse(w, r)
+			return
+		}
+	}
+
+	if err := app.store.Roles.SetPermissions(r.Context(), id, payload.Permissions); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		case store.ErrUnknownPermission:
+			app.badRequestResponse(w, r, errors.New("alguno de los permisos no existe"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.invalidateRoleUsers(r.Context(), id)
+
+	role, err = app.store.Roles.GetByID(r.Context(), id)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, role)
+}
+
+func (app *application) listPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	permissions, err := app.store.Roles.GetPermissions(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, permissions)
+}
@@ -0,0 +1,141 @@
+// cmd/api/sessions.go
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"GopherSocial/internal/store"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// deviceFromUserAgent da un nombre legible al dispositivo ("Firefox en Linux").
+// Es solo orientativo: el user agent completo también se guarda.
+func deviceFromUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	browser := "Navegador desconocido"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/"), strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/"), strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "curl/"):
+		browser = "curl"
+	case ua == "":
+		browser = "Cliente desconocido"
+	}
+
+	os := ""
+	switch {
+	case strings.Contains(ua, "android"):
+		os = "Android"
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"):
+		os = "iOS"
+	case strings.Contains(ua, "windows"):
+		os = "Windows"
+	case strings.Contains(ua, "mac os"):
+		os = "macOS"
+	case strings.Contains(ua, "linux"):
+		os = "Linux"
+	}
+
+	if os == "" {
+		return browser
+	}
+	return browser + " en " + os
+}
+
+// currentSessionID devuelve la sesión del access token de la petición ("" si no tiene).
+func currentSessionID(r *http.Request) string {
+	claims, _ := r.Context().Value(claimsCtxKey).(jwt.MapClaims)
+	sid, _ := claims["sid"].(string)
+	return sid
+}
+
+// listSessionsHandler lista las sesiones abiertas del usuario y marca la actual.
+func (app *application) listSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	sessions, err := app.store.Sessions.GetActiveByUserID(r.Context(), user.ID, app.config.auth.refreshExp)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	current := currentSessionID(r)
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == current
+	}
+
+	app.jsonResponse(w, http.StatusOK, sessions)
+}
+
+// revokeSessionHandler cierra una sesión del usuario (por ejemplo, un dispositivo perdido).
+func (app *application) revokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	sessionID := chi.URLParam(r, "sessionID")
+	if _, err := uuid.Parse(sessionID); err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if err := app.store.Sessions.Revoke(r.Context(), user.ID, sessionID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	// Sus access tokens dejan de valer aunque no hayan expirado.
+	if err := app.cacheStorage.Tokens.RevokeSession(r.Context(), sessionID, app.config.auth.exp); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// revokeAllSessionsHandler cierra todas las sesiones del usuario, incluida la
+// actual, y revoca sus tokens personales.
+func (app *application) revokeAllSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	revoked, err := app.store.Sessions.RevokeAll(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.revokeSessionTokens(r.Context(), revoked); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// revokeSessionTokens invalida los access tokens de las sesiones cerradas
+// (claim "sid"), que si no seguirían valiendo hasta expirar.
+func (app *application) revokeSessionTokens(ctx context.Context, sessionIDs []string) error {
+	for _, sessionID := range sessionIDs {
+		if err := app.cacheStorage.Tokens.RevokeSession(ctx, sessionID, app.config.auth.exp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
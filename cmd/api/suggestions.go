@@ -0,0 +1,53 @@
+// cmd/api/suggestions.go
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"GopherSocial/internal/store"
+)
+
+// maxSuggestions es cuántas sugerencias calculamos y guardamos por usuario;
+// cada petición devuelve las primeras ?limit= de ellas.
+const maxSuggestions = 50
+
+// getSuggestionsHandler devuelve usuarios que el autenticado quizá conozca.
+// El cálculo se guarda en Redis durante SUGGESTIONS_REFRESH_INTERVAL.
+func (app *application) getSuggestionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	limit := 10
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > maxSuggestions {
+			app.badRequestResponse(w, r, errors.New("limit debe ser un número entre 1 y 50"))
+			return
+		}
+		limit = n
+	}
+
+	suggestions, err := app.cacheStorage.Suggestions.Get(r.Context(), user.ID)
+	if err != nil {
+		app.logger.Printf("ERROR: no se pudieron leer las sugerencias de la caché: %s", err)
+	}
+
+	if suggestions == nil {
+		suggestions, err = app.store.Followers.GetSuggestions(r.Context(), user.ID, maxSuggestions)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		if err := app.cacheStorage.Suggestions.Set(r.Context(), user.ID, suggestions, app.config.suggestions.refresh); err != nil {
+			app.logger.Printf("ERROR: no se pudieron guardar las sugerencias en la caché: %s", err)
+		}
+	}
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	app.jsonResponse(w, http.StatusOK, suggestions)
+}
@@ -0,0 +1,64 @@
+// cmd/api/sweeper.go
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// runInvitationSweeper limpia periódicamente las invitaciones caducadas, las
+// cuentas borradas cuyo periodo de gracia terminó y, si hay un periodo de
+// gracia configurado, las cuentas que nunca se activaron.
+// Se ejecuta en su propia goroutine hasta que se cancela el contexto.
+// Con un intervalo de 0 o negativo no hace nada (time.NewTicker entraría en pánico).
+func (app *application) runInvitationSweeper(ctx context.Context) {
+	if app.config.sweeper.interval <= 0 {
+		app.logger.Printf("INFO: SWEEPER_INTERVAL es %s, la limpieza periódica está desactivada", app.config.sweeper.interval)
+		return
+	}
+
+	ticker := time.NewTicker(app.config.sweeper.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			app.sweepInvitations(ctx)
+			app.sweepDeletedAccounts(ctx)
+		}
+	}
+}
+
+func (app *application) sweepInvitations(ctx context.Context) {
+	// Las invitaciones de las cuentas borradas desaparecen con ON DELETE CASCADE.
+	if grace := app.config.sweeper.unactivatedGrace; grace > 0 {
+		n, err := app.store.Users.DeleteUnactivated(ctx, grace)
+		if err != nil {
+			app.logger.Printf("ERROR: no se pudieron borrar las cuentas sin activar: %s", err)
+		} else if n > 0 {
+			app.logger.Printf("INFO: borradas %d cuentas sin activar", n)
+		}
+	}
+
+	n, err := app.store.Users.DeleteExpiredInvitations(ctx)
+	if err != nil {
+		app.logger.Printf("ERROR: no se pudieron borrar las invitaciones caducadas: %s", err)
+		return
+	}
+	if n > 0 {
+		app.logger.Printf("INFO: borradas %d invitaciones caducadas", n)
+	}
+}
+
+func (app *application) sweepDeletedAccounts(ctx context.Context) {
+	n, err := app.store.Users.PurgeDeleted(ctx, app.config.sweeper.deletedGrace)
+	if err != nil {
+		app.logger.Printf("ERROR: no se pudieron purgar las cuentas borradas: %s", err)
+		return
+	}
+	if n > 0 {
+		app.logger.Printf("INFO: purgadas %d cuentas borradas", n)
+	}
+}
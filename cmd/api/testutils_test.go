@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"GopherSocial/internal/auth"
+	"GopherSocial/internal/store"
+	"GopherSocial/internal/store/cache"
+)
+
+// newTestApplication crea una aplicación sin DB ni Redis: las cachés son
+// stubs en memoria y cada test sustituye las partes del store que necesite.
+func newTestApplication(t *testing.T) *application {
+	t.Helper()
+
+	app := &application{
+		authenticator: auth.NewJWTAuthenticator("test-secret", "gophersocial", "gophersocial", 30*time.Second),
+		cacheStorage: cache.Storage{
+			Users:       &stubUserCache{users: map[int64]*store.User{}},
+			Tokens:      &stubTokenDenylist{},
+			Suggestions: &stubSuggestionCache{},
+		},
+		logger: log.New(io.Discard, "", 0),
+	}
+	app.config.auth.exp = 15 * time.Minute
+
+	return app
+}
+
+// executeRequest pasa la petición por el handler y devuelve la respuesta grabada.
+func executeRequest(req *http.Request, h http.Handler) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func checkResponseCode(t *testing.T, expected, actual int) {
+	t.Helper()
+	if expected != actual {
+		t.Errorf("expected the response code to be %d and we got %d", expected, actual)
+	}
+}
+
+// stubUserCache es una caché de usuarios en memoria.
+type stubUserCache struct {
+	mu    sync.Mutex
+	users map[int64]*store.User
+}
+
+func (c *stubUserCache) Get(_ context.Context, id int64) (*store.User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.users[id], nil
+}
+
+func (c *stubUserCache) Set(_ context.Context, user *store.User) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.users[user.ID] = user
+	return nil
+}
+
+func (c *stubUserCache) Delete(_ context.Context, id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.users, id)
+}
+
+// stubTokenDenylist guarda las revocaciones en memoria.
+type stubTokenDenylist struct {
+	mu       sync.Mutex
+	tokens   map[string]bool
+	sessions map[string]bool
+}
+
+func (d *stubTokenDenylist) Revoke(_ context.Context, jti string, _ time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.tokens == nil {
+		d.tokens = map[string]bool{}
+	}
+	d.tokens[jti] = true
+	return nil
+}
+
+func (d *stubTokenDenylist) IsRevoked(_ context.Context, jti string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.tokens[jti], nil
+}
+
+func (d *stubTokenDenylist) RevokeSession(_ context.Context, sessionID string, _ time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.sessions == nil {
+		d.sessions = map[string]bool{}
+	}
+	d.sessions[sessionID] = true
+	return nil
+}
+
+func (d *stubTokenDenylist) IsSessionRevoked(_ context.Context, sessionID string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.sessions[sessionID], nil
+}
+
+// stubSuggestionCache es una caché de sugerencias que nunca tiene nada guardado.
+type stubSuggestionCache struct{}
+
+func (c *stubSuggestionCache) Get(context.Context, int64) ([]store.Suggestion, error) {
+	return nil, nil
+}
+
+func (c *stubSuggestionCache) Set(context.Context, int64, []store.Suggestion, time.Duration) error {
+	return nil
+}
+
+func (c *stubSuggestionCache) Delete(context.Context, int64) {}
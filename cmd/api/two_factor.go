@@ -0,0 +1,334 @@
+// cmd/api/two_factor.go
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"GopherSocial/internal/auth"
+	"GopherSocial/internal/store"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	totpIssuer         = "GopherSocial" // Nombre que muestran las apps de autenticación
+	recoveryCodesCount = 10
+	// Los tokens de reto usan otra audience para que nunca valgan como access token.
+	twoFactorAudienceSuffix = ":2fa"
+	twoFactorChallengeExp   = time.Minute * 5
+)
+
+// generateTwoFactorChallenge firma el token de vida corta que devuelve el primer
+// paso del login cuando el usuario tiene 2FA activado.
+func (app *application) generateTwoFactorChallenge(user *store.User) (string, error) {
+	claims := jwt.RegisteredClaims{
+		ID:        uuid.New().String(),
+		Subject:   fmt.Sprintf("%d", user.ID),
+		Issuer:    app.authenticator.Issuer(),
+		Audience:  jwt.ClaimStrings{app.authenticator.Audience() + twoFactorAudienceSuffix},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(twoFactorChallengeExp)),
+		NotBefore: jwt.NewNumericDate(time.Now()),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+
+	return app.authenticator.GenerateToken(claims)
+}
+
+type TwoFactorLoginPayload struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode   string `json:"recovery_code" validate:"required_without=Code,omitempty,max=32"`
+}
+
+// twoFactorLoginHandler es el segundo paso del login: cambia el token de reto
+// y un código TOTP (o de recuperación) por los tokens de sesión.
+func (app *application) twoFactorLoginHandler(w http.ResponseWriter, r *http.Request) {
+	var payload TwoFactorLoginPayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	challenge, err := app.authenticator.ValidateTokenForAudience(payload.ChallengeToken, app.authenticator.Audience()+twoFactorAudienceSuffix)
+	if err != nil {
+		app.unauthorizedErrorResponse(w, r)
+		return
+	}
+
+	claims, ok := challenge.Claims.(jwt.MapClaims)
+	if !ok {
+		app.unauthorizedErrorResponse(w, r)
+		return
+	}
+
+	// Un reto solo se puede canjear una vez.
+	jti, _ := claims["jti"].(string)
+	revoked, err := app.cacheStorage.Tokens.IsRevoked(r.Context(), jti)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if revoked {
+		app.unauthorizedErrorResponse(w, r)
+		return
+	}
+
+	userIDStr, err := claims.GetSubject()
+	if err != nil {
+		app.unauthorizedErrorResponse(w, r)
+		return
+	}
+	userID, _ := strconv.ParseInt(userIDStr, 10, 64)
+
+	// Vamos directo a la DB: el secreto TOTP nunca se guarda en la caché.
+	user, err := app.store.Users.GetByID(r.Context(), userID)
+	if err != nil || !user.IsActive || !user.TwoFactorEnabled {
+		app.unauthorizedErrorResponse(w, r)
+		return
+	}
+	if user.IsBanned() {
+		app.bannedResponse(w, r, user)
+		return
+	}
+
+	// Los códigos de 6 dígitos también se pueden adivinar: aplicamos el mismo bloqueo que al login.
+	ip := clientIP(r)
+	lockedFor, err := app.loginLockedFor(r.Context(), user.Email, ip)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if lockedFor > 0 {
+		app.auditLoginFailure(r.Context(), user.Email, ip, user, store.LoginFailureLocked)
+		app.accountLockedResponse(w, r, lockedFor)
+		return
+	}
+
+	if payload.Code != "" {
+		valid, err := app.useTOTPCode(r.Context(), user, payload.Code)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if !valid {
+			app.registerLoginFailure(r.Context(), user.Email, ip, user, store.LoginFailureBad2FACode)
+			app.unauthorizedErrorResponse(w, r)
+			return
+		}
+	} else {
+		err := app.store.Users.UseRecoveryCode(r.Context(), user.ID, hashRecoveryCode(payload.RecoveryCode))
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				app.registerLoginFailure(r.Context(), user.Email, ip, user, store.LoginFailureBad2FACode)
+				app.unauthorizedErrorResponse(w, r)
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+	app.resetLoginFailures(r.Context(), user.Email)
+
+	if err := app.revokeAccessToken(r.Context(), claims); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	tokens, err := app.issueTokens(r, user)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusCreated, tokens)
+}
+
+// enrollTwoFactorHandler genera un secreto TOTP nuevo y devuelve la URI otpauth://
+// para mostrarla como QR. 2FA no se activa hasta verificar el primer código.
+func (app *application) enrollTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.Users.SetTOTPSecret(r.Context(), user.ID, secret); err != nil {
+		switch err {
+		case store.ErrConflict:
+			app.conflictResponse(w, r, errors.New("la verificación en dos pasos ya está activada"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]string{
+		"secret":      secret,
+		"otpauth_uri": auth.TOTPURI(totpIssuer, user.Email, secret),
+	})
+}
+
+type TwoFactorCodePayload struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// verifyTwoFactorHandler activa 2FA si el código es válido y devuelve los
+// códigos de recuperación. Es la única vez que se muestran en claro.
+func (app *application) verifyTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user, payload, ok := app.readTwoFactorCode(w, r)
+	if !ok {
+		return
+	}
+
+	if user.TwoFactorEnabled {
+		app.conflictResponse(w, r, errors.New("la verificación en dos pasos ya está activada"))
+		return
+	}
+	if user.TOTPSecret == "" {
+		app.badRequestResponse(w, r, errors.New("primero debes iniciar la inscripción"))
+		return
+	}
+	valid, err := app.useTOTPCode(r.Context(), user, payload.Code)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !valid {
+		app.badRequestResponse(w, r, errors.New("código inválido"))
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes(recoveryCodesCount)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.Users.EnableTwoFactor(r.Context(), user.ID, hashes); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.cacheStorage.Users.Delete(r.Context(), user.ID)
+
+	app.jsonResponse(w, http.StatusOK, map[string]any{"recovery_codes": codes})
+}
+
+// disableTwoFactorHandler desactiva 2FA pidiendo un código válido, salvo que el rol lo exija.
+func (app *application) disableTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user, payload, ok := app.readTwoFactorCode(w, r)
+	if !ok {
+		return
+	}
+
+	if user.Role.Require2FA {
+		app.forbiddenResponse(w, r)
+		return
+	}
+	if !user.TwoFactorEnabled {
+		app.badRequestResponse(w, r, errors.New("código inválido"))
+		return
+	}
+	valid, err := app.useTOTPCode(r.Context(), user, payload.Code)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !valid {
+		app.badRequestResponse(w, r, errors.New("código inválido"))
+		return
+	}
+
+	if err := app.store.Users.DisableTwoFactor(r.Context(), user.ID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.cacheStorage.Users.Delete(r.Context(), user.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// useTOTPCode comprueba el código TOTP del usuario y lo consume: un código ya
+// aceptado, o uno de un periodo anterior, no vuelve a valer.
+func (app *application) useTOTPCode(ctx context.Context, user *store.User, code string) (bool, error) {
+	counter, ok := auth.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	if err := app.store.Users.UseTOTPCounter(ctx, user.ID, counter); err != nil {
+		if errors.Is(err, store.ErrConflict) {
+			return false, nil // Reenvío de un código ya usado
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// readTwoFactorCode lee y valida el código del body y recarga al usuario desde la
+// DB (con su secreto). Si algo falla ya respondió y devuelve ok = false.
+func (app *application) readTwoFactorCode(w http.ResponseWriter, r *http.Request) (*store.User, TwoFactorCodePayload, bool) {
+	ctxUser := r.Context().Value(userCtxKey).(*store.User)
+
+	var payload TwoFactorCodePayload
+	if err := app.readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return nil, payload, false
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return nil, payload, false
+	}
+
+	user, err := app.store.Users.GetByID(r.Context(), ctxUser.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return nil, payload, false
+	}
+
+	return user, payload, true
+}
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRecoveryCodes genera n códigos con forma "xxxxx-xxxxx" y sus hashes.
+func newRecoveryCodes(n int) ([]string, [][]byte, error) {
+	codes := make([]string, n)
+	hashes := make([][]byte, n)
+
+	for i := range n {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(raw))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode normaliza el código (sin guiones ni mayúsculas) antes de hashearlo.
+func hashRecoveryCode(code string) []byte {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	hash := sha256.Sum256([]byte(normalized))
+	return hash[:]
+}
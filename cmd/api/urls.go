@@ -0,0 +1,53 @@
+// cmd/api/urls.go
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// activationURL devuelve el enlace de activación que enviamos por correo.
+// Si hay un frontend configurado, el enlace lleva a su página /confirm/{token},
+// que se encarga de llamar a PUT /v1/users/activate/{token}.
+func (app *application) activationURL(token string) string {
+	if base := app.frontendBaseURL(); base != "" {
+		return fmt.Sprintf("%s/confirm/%s", base, url.PathEscape(token))
+	}
+	return fmt.Sprintf("%s/v1/users/activate/%s", app.apiBaseURL(), url.PathEscape(token))
+}
+
+// passwordResetURL devuelve el enlace para restablecer la contraseña.
+// Con frontend, su página /password/reset/{token} llama a PUT /v1/authentication/password/reset/{token}.
+func (app *application) passwordResetURL(token string) string {
+	if base := app.frontendBaseURL(); base != "" {
+		return fmt.Sprintf("%s/password/reset/%s", base, url.PathEscape(token))
+	}
+	return fmt.Sprintf("%s/v1/authentication/password/reset/%s", app.apiBaseURL(), url.PathEscape(token))
+}
+
+// emailChangeURL devuelve el enlace para confirmar un nuevo email.
+// Con frontend, su página /email/confirm/{token} llama a PUT /v1/users/email/confirm/{token}.
+func (app *application) emailChangeURL(token string) string {
+	if base := app.frontendBaseURL(); base != "" {
+		return fmt.Sprintf("%s/email/confirm/%s", base, url.PathEscape(token))
+	}
+	return fmt.Sprintf("%s/v1/users/email/confirm/%s", app.apiBaseURL(), url.PathEscape(token))
+}
+
+// forgotPasswordURL devuelve la página del frontend para pedir un restablecimiento.
+// La API no tiene una página equivalente, así que sin frontend devuelve "".
+func (app *application) forgotPasswordURL() string {
+	if base := app.frontendBaseURL(); base != "" {
+		return base + "/password/forgot"
+	}
+	return ""
+}
+
+func (app *application) apiBaseURL() string {
+	return strings.TrimRight(app.config.apiURL, "/")
+}
+
+func (app *application) frontendBaseURL() string {
+	return strings.TrimRight(app.config.frontendURL, "/")
+}
@@ -0,0 +1,116 @@
+package main
+
+import "testing"
+
+func TestEmailURLs(t *testing.T) {
+	const token = "abc123"
+
+	tests := []struct {
+		name        string
+		apiURL      string
+		frontendURL string
+		token       string
+		activation  string
+		reset       string
+		emailChange string
+		forgot      string
+	}{
+		{
+			name:        "api without trailing slash",
+			apiURL:      "https://api.example.com",
+			token:       token,
+			activation:  "https://api.example.com/v1/users/activate/abc123",
+			reset:       "https://api.example.com/v1/authentication/password/reset/abc123",
+			emailChange: "https://api.example.com/v1/users/email/confirm/abc123",
+			forgot:      "",
+		},
+		{
+			name:        "api with trailing slash",
+			apiURL:      "https://api.example.com/",
+			token:       token,
+			activation:  "https://api.example.com/v1/users/activate/abc123",
+			reset:       "https://api.example.com/v1/authentication/password/reset/abc123",
+			emailChange: "https://api.example.com/v1/users/email/confirm/abc123",
+			forgot:      "",
+		},
+		{
+			name:        "api with path prefix",
+			apiURL:      "https://example.com/social/",
+			token:       token,
+			activation:  "https://example.com/social/v1/users/activate/abc123",
+			reset:       "https://example.com/social/v1/authentication/password/reset/abc123",
+			emailChange: "https://example.com/social/v1/users/email/confirm/abc123",
+			forgot:      "",
+		},
+		{
+			name:        "frontend without trailing slash",
+			apiURL:      "https://api.example.com",
+			frontendURL: "https://app.example.com",
+			token:       token,
+			activation:  "https://app.example.com/confirm/abc123",
+			reset:       "https://app.example.com/password/reset/abc123",
+			emailChange: "https://app.example.com/email/confirm/abc123",
+			forgot:      "https://app.example.com/password/forgot",
+		},
+		{
+			name:        "frontend with trailing slash",
+			apiURL:      "https://api.example.com",
+			frontendURL: "https://app.example.com/",
+			token:       token,
+			activation:  "https://app.example.com/confirm/abc123",
+			reset:       "https://app.example.com/password/reset/abc123",
+			emailChange: "https://app.example.com/email/confirm/abc123",
+			forgot:      "https://app.example.com/password/forgot",
+		},
+		{
+			name:        "frontend with path prefix",
+			apiURL:      "https://api.example.com",
+			frontendURL: "https://example.com/app/",
+			token:       token,
+			activation:  "https://example.com/app/confirm/abc123",
+			reset:       "https://example.com/app/password/reset/abc123",
+			emailChange: "https://example.com/app/email/confirm/abc123",
+			forgot:      "https://example.com/app/password/forgot",
+		},
+		{
+			name:        "token is escaped",
+			apiURL:      "https://api.example.com",
+			token:       "a/b c?d#e",
+			activation:  "https://api.example.com/v1/users/activate/a%2Fb%20c%3Fd%23e",
+			reset:       "https://api.example.com/v1/authentication/password/reset/a%2Fb%20c%3Fd%23e",
+			emailChange: "https://api.example.com/v1/users/email/confirm/a%2Fb%20c%3Fd%23e",
+			forgot:      "",
+		},
+		{
+			name:        "token is escaped in frontend links",
+			apiURL:      "https://api.example.com",
+			frontendURL: "https://app.example.com",
+			token:       "a/b c?d#e",
+			activation:  "https://app.example.com/confirm/a%2Fb%20c%3Fd%23e",
+			reset:       "https://app.example.com/password/reset/a%2Fb%20c%3Fd%23e",
+			emailChange: "https://app.example.com/email/confirm/a%2Fb%20c%3Fd%23e",
+			forgot:      "https://app.example.com/password/forgot",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &application{}
+			app.config.apiURL = tt.apiURL
+			app.config.frontendURL = tt.frontendURL
+
+			if got := app.activationURL(tt.token); got != tt.activation {
+				t.Errorf("activationURL = %q, want %q", got, tt.activation)
+			}
+			if got := app.passwordResetURL(tt.token); got != tt.reset {
+				t.Errorf("passwordResetURL = %q, want %q", got, tt.reset)
+			}
+			if got := app.emailChangeURL(tt.token); got != tt.emailChange {
+				t.Errorf("emailChangeURL = %q, want %q", got, tt.emailChange)
+			}
+			if got := app.forgotPasswordURL(); got != tt.forgot {
+				t.Errorf("forgotPasswordURL = %q, want %q", got, tt.forgot)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -23,17 +25,31 @@ func (app *application) followUserHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	// 3. Llamamos a nuestra lógica del store.
-	err = app.store.Followers.Follow(r.Context(), followedID, followerUser.ID)
+	pending, err := app.store.Followers.Follow(r.Context(), followedID, followerUser.ID)
 	if err != nil {
-		if err == store.ErrConflict {
-			app.conflictResponse(w, r, err)
-			return
+		switch err {
+		case store.ErrSelfFollow:
+			app.badRequestResponse(w, r, errors.New("no puedes seguirte a ti mismo"))
+		case store.ErrBlocked:
+			app.forbiddenResponse(w, r)
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r)
+		case store.ErrConflict:
+			app.conflictResponse(w, r, errors.New("ya sigues a este usuario o tu solicitud está pendiente"))
+		default:
+			app.internalServerError(w, r, err)
 		}
-		app.internalServerError(w, r, err)
 		return
 	}
 
-	// 4. Respondemos con éxito.
+	// Ya no tiene sentido sugerirle a este usuario.
+	app.cacheStorage.Suggestions.Delete(r.Context(), followerUser.ID)
+
+	// 4. Respondemos con éxito. Las cuentas privadas tienen que aprobar la solicitud.
+	if pending {
+		app.jsonResponse(w, http.StatusAccepted, map[string]string{"status": "requested"})
+		return
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -49,10 +65,112 @@ func (app *application) unfollowUserHandler(w http.ResponseWriter, r *http.Reque
 	}
 
 	err = app.store.Followers.Unfollow(r.Context(), unfollowedID, followerUser.ID)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r) // No lo seguía
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.cacheStorage.Suggestions.Delete(r.Context(), followerUser.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getFollowersHandler lista los seguidores de un usuario, paginados.
+func (app *application) getFollowersHandler(w http.ResponseWriter, r *http.Request) {
+	app.listFollows(w, r, app.store.Followers.GetFollowers)
+}
+
+// getFollowingHandler lista a quién sigue un usuario, paginado.
+func (app *application) getFollowingHandler(w http.ResponseWriter, r *http.Request) {
+	app.listFollows(w, r, app.store.Followers.GetFollowing)
+}
+
+// getMutualFollowersHandler lista los seguidores del usuario a los que también
+// sigue el usuario autenticado.
+func (app *application) getMutualFollowersHandler(w http.ResponseWriter, r *http.Request) {
+	app.listFollows(w, r, app.store.Followers.GetMutualFollowers)
+}
+
+type followLister func(ctx context.Context, userID, viewerID int64, q store.PaginatedQuery) ([]store.FollowUser, string, error)
+
+// listFollows es el flujo común de los listados de seguidores. De una cuenta
+// privada solo los ven quienes pueden ver sus publicaciones.
+func (app *application) listFollows(w http.ResponseWriter, r *http.Request, list followLister) {
+	viewer := r.Context().Value(userCtxKey).(*store.User)
+
+	user, ok := app.readVisibleUser(w, r)
+	if !ok {
+		return
+	}
+
+	visible, err := app.store.Followers.CanSeePosts(r.Context(), user.ID, viewer.ID)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
+	if !visible {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	q, err := app.readPaginatedQuery(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	users, nextCursor, err := list(r.Context(), user.ID, viewer.ID, q)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.paginatedResponse(w, http.StatusOK, users, nextCursor)
+}
+
+// listFollowRequestsHandler lista las solicitudes pendientes para seguir al usuario autenticado.
+func (app *application) listFollowRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	app.listUserSummaries(w, r, app.store.Followers.GetRequests)
+}
+
+// approveFollowRequestHandler acepta la solicitud del usuario de la URL.
+func (app *application) approveFollowRequestHandler(w http.ResponseWriter, r *http.Request) {
+	app.answerFollowRequest(w, r, app.store.Followers.ApproveRequest)
+}
+
+// rejectFollowRequestHandler descarta la solicitud del usuario de la URL.
+func (app *application) rejectFollowRequestHandler(w http.ResponseWriter, r *http.Request) {
+	app.answerFollowRequest(w, r, app.store.Followers.RejectRequest)
+}
+
+func (app *application) answerFollowRequest(w http.ResponseWriter, r *http.Request, answer func(context.Context, int64, int64) error) {
+	user := r.Context().Value(userCtxKey).(*store.User)
+
+	requesterID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := answer(r.Context(), user.ID, requesterID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundResponse(w, r) // No había solicitud
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	// La solicitud pendiente excluía al usuario de las sugerencias del solicitante
+	// y, si se aprueba, cambian los seguidos de ambos.
+	app.cacheStorage.Suggestions.Delete(r.Context(), requesterID)
+	app.cacheStorage.Suggestions.Delete(r.Context(), user.ID)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_posts_search;
+
+DROP INDEX IF EXISTS idx_posts_created_at_id;
@@ -0,0 +1,7 @@
+-- Índice para recorrer las publicaciones por (created_at, id) con paginación por cursor
+CREATE INDEX IF NOT EXISTS idx_posts_created_at_id ON posts (created_at, id);
+
+-- Índice para la búsqueda de texto completo del feed
+CREATE INDEX IF NOT EXISTS idx_posts_search ON posts USING gin (
+    to_tsvector('simple', title || ' ' || content)
+);
@@ -0,0 +1,5 @@
+-- Permite encontrar rápidamente a quién sigue un usuario (usado por el feed)
+CREATE INDEX IF NOT EXISTS idx_followers_follower_id ON followers (follower_id, user_id);
+
+-- Permite contar los comentarios de cada publicación sin recorrer toda la tabla
+CREATE INDEX IF NOT EXISTS idx_comments_post_id ON comments (post_id);
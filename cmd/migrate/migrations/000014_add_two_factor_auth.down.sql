@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS user_recovery_codes;
+
+ALTER TABLE roles DROP COLUMN require_2fa;
+
+ALTER TABLE users DROP COLUMN totp_enabled, DROP COLUMN totp_secret;
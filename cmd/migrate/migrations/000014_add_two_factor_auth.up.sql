@@ -0,0 +1,17 @@
+-- Secreto TOTP del usuario. Se guarda al iniciar la inscripción y
+-- totp_enabled solo pasa a TRUE cuando el usuario verifica el primer código.
+ALTER TABLE users
+ADD COLUMN totp_secret text,
+ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Permite exigir 2FA a todos los usuarios de un rol (ej. moderadores y admins)
+ALTER TABLE roles ADD COLUMN require_2fa BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    -- Hash SHA-256 del código de recuperación, nunca el código en claro
+    code bytea NOT NULL,
+    used_at timestamp(0) with time zone,
+    UNIQUE (user_id, code)
+);
@@ -0,0 +1,14 @@
+-- Registro de auditoría de los intentos de login fallidos
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id bigserial PRIMARY KEY,
+    email citext NOT NULL,
+    -- NULL si el email no corresponde a ningún usuario
+    user_id bigint REFERENCES users (id) ON DELETE SET NULL,
+    ip text NOT NULL,
+    reason varchar(50) NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_email ON login_attempts (email, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip ON login_attempts (ip, created_at);
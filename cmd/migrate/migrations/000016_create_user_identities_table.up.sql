@@ -0,0 +1,13 @@
+-- Vincula cuentas de proveedores externos (OIDC) con nuestros usuarios
+CREATE TABLE IF NOT EXISTS user_identities (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider varchar(100) NOT NULL,
+    -- El claim "sub" del proveedor: es el identificador estable del usuario allí
+    subject varchar(255) NOT NULL,
+    email citext,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id);
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS permissions (
+    id bigserial PRIMARY KEY,
+    name varchar(100) NOT NULL UNIQUE,
+    description text NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id bigint NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission_id bigint NOT NULL REFERENCES permissions (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+-- Permisos por defecto. Los dueños de un recurso no necesitan permisos para
+-- gestionarlo; estos permisos son para actuar sobre recursos ajenos.
+INSERT INTO permissions (name, description) VALUES
+    ('post.update.any', 'Editar posts de otros usuarios'),
+    ('post.delete.any', 'Borrar posts de otros usuarios'),
+    ('comment.update.any', 'Editar comentarios de otros usuarios'),
+    ('comment.delete.any', 'Borrar comentarios de otros usuarios'),
+    ('user.ban', 'Suspender y reactivar usuarios'),
+    ('user.role.update', 'Cambiar el rol de un usuario'),
+    ('role.manage', 'Crear y editar roles y sus permisos');
+
+-- Equivalente a los antiguos niveles: el moderador (2) podía editar posts
+-- ajenos y el admin (3) además borrarlos.
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'moderator'
+  AND p.name IN ('post.update.any', 'comment.update.any', 'comment.delete.any');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'admin';
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS audit_logs;
+
+ALTER TABLE users DROP COLUMN IF EXISTS banned_until;
+
+ALTER TABLE users DROP COLUMN IF EXISTS ban_reason;
+
+ALTER TABLE users DROP COLUMN IF EXISTS banned_at;
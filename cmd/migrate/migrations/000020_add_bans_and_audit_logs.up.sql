@@ -0,0 +1,20 @@
+-- Una suspensión no toca is_active: los usuarios inactivos los borra el barrido
+-- de invitaciones caducadas.
+ALTER TABLE users ADD COLUMN banned_at timestamp(0) with time zone;
+
+ALTER TABLE users ADD COLUMN ban_reason text NOT NULL DEFAULT '';
+
+-- NULL = suspensión indefinida
+ALTER TABLE users ADD COLUMN banned_until timestamp(0) with time zone;
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id bigserial PRIMARY KEY,
+    actor_id bigint REFERENCES users (id) ON DELETE SET NULL,
+    action varchar(100) NOT NULL,
+    target_type varchar(50) NOT NULL,
+    target_id bigint NOT NULL,
+    metadata jsonb NOT NULL DEFAULT '{}',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at, id);
@@ -0,0 +1,7 @@
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_url;
+
+ALTER TABLE users DROP COLUMN IF EXISTS bio;
+
+ALTER TABLE users DROP COLUMN IF EXISTS display_name;
@@ -0,0 +1,8 @@
+ALTER TABLE users ADD COLUMN display_name varchar(100) NOT NULL DEFAULT '';
+
+ALTER TABLE users ADD COLUMN bio text NOT NULL DEFAULT '';
+
+ALTER TABLE users ADD COLUMN avatar_url text NOT NULL DEFAULT '';
+
+-- Borrado lógico: la cuenta se borra de verdad al terminar el periodo de gracia.
+ALTER TABLE users ADD COLUMN deleted_at timestamp(0) with time zone;
@@ -0,0 +1,5 @@
+-- Listado de seguidores de un usuario, ordenado por fecha de seguimiento
+CREATE INDEX IF NOT EXISTS idx_followers_user_id_created_at ON followers (user_id, created_at, follower_id);
+
+-- Listado de a quién sigue un usuario, ordenado por fecha de seguimiento
+CREATE INDEX IF NOT EXISTS idx_followers_follower_id_created_at ON followers (follower_id, created_at, user_id);
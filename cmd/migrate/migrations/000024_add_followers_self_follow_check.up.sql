@@ -0,0 +1,4 @@
+-- Antes no se validaba: limpiamos los auto-seguimientos que pudiera haber.
+DELETE FROM followers WHERE user_id = follower_id;
+
+ALTER TABLE followers ADD CONSTRAINT followers_no_self_follow CHECK (user_id <> follower_id);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS user_blocks (
+    -- Quién bloquea
+    blocker_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    -- A quién bloquea
+    blocked_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (blocker_id, blocked_id),
+    CONSTRAINT user_blocks_no_self_block CHECK (blocker_id <> blocked_id)
+);
+
+-- Para comprobar el bloqueo en la otra dirección
+CREATE INDEX IF NOT EXISTS idx_user_blocks_blocked_id ON user_blocks (blocked_id, blocker_id);
+
+CREATE TABLE IF NOT EXISTS user_mutes (
+    -- Quién silencia
+    muter_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    -- A quién silencia
+    muted_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (muter_id, muted_id),
+    CONSTRAINT user_mutes_no_self_mute CHECK (muter_id <> muted_id)
+);
@@ -0,0 +1,14 @@
+ALTER TABLE users ADD COLUMN is_private BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Solicitudes pendientes para seguir a una cuenta privada
+CREATE TABLE IF NOT EXISTS follow_requests (
+    -- El usuario al que se quiere seguir
+    user_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    -- El usuario que lo solicita
+    requester_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, requester_id),
+    CONSTRAINT follow_requests_no_self_request CHECK (user_id <> requester_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_follow_requests_user_id_created_at ON follow_requests (user_id, created_at, requester_id);
@@ -0,0 +1,18 @@
+-- Una reacción por usuario y publicación: reaccionar de nuevo cambia el tipo.
+CREATE TABLE IF NOT EXISTS post_reactions (
+    post_id bigint NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    user_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind varchar(16) NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (post_id, user_id),
+    CONSTRAINT post_reactions_kind_check CHECK (kind IN ('like', 'love', 'haha', 'wow', 'sad', 'angry'))
+);
+
+CREATE TABLE IF NOT EXISTS comment_reactions (
+    comment_id bigint NOT NULL REFERENCES comments (id) ON DELETE CASCADE,
+    user_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind varchar(16) NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (comment_id, user_id),
+    CONSTRAINT comment_reactions_kind_check CHECK (kind IN ('like', 'love', 'haha', 'wow', 'sad', 'angry'))
+);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_comments_parent_id;
+
+DROP INDEX IF EXISTS idx_comments_post_top_level;
+
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_depth_check;
+
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS depth,
+    DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,14 @@
+-- Respuestas anidadas: depth 0 son los comentarios de primer nivel.
+-- Borrar un comentario borra también todas sus respuestas.
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS parent_id bigint REFERENCES comments (id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS depth smallint NOT NULL DEFAULT 0;
+
+-- Debe coincidir con store.MaxCommentDepth
+ALTER TABLE comments
+    ADD CONSTRAINT comments_depth_check CHECK (depth BETWEEN 0 AND 3);
+
+-- Comentarios de primer nivel de un post y respuestas de un comentario, paginados
+CREATE INDEX IF NOT EXISTS idx_comments_post_top_level ON comments (post_id, created_at, id) WHERE parent_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id, created_at, id);
@@ -0,0 +1,5 @@
+-- version para el bloqueo optimista (como en posts) y edited_at para marcar
+-- los comentarios editados.
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS edited_at timestamp(0) with time zone;
@@ -0,0 +1,3 @@
+-- Último periodo TOTP aceptado: un código ya usado (o uno anterior) no se
+-- puede volver a presentar mientras siga dentro del margen de desfase.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_counter bigint;
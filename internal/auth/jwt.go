@@ -2,35 +2,161 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+var (
+	// ErrMissingIssuedAt indica que el token no trae el claim iat.
+	ErrMissingIssuedAt = errors.New("token is missing the iat claim")
+	// ErrUnknownKey indica que el kid del token no corresponde a ninguna de nuestras claves.
+	ErrUnknownKey = errors.New("token signed with an unknown key")
+)
+
 type JWTAuthenticator struct {
-	secret string
-	iss    string // Issuer (quién emite el token)
-	aud    string // Audience (para quién es el token)
+	secret    string                 // Secreto HS256; vacío desactiva HS256
+	keys      map[string]*SigningKey // Claves asimétricas por kid
+	activeKID string                 // Clave con la que firmamos; vacío firma con HS256
+	iss       string                 // Issuer (quién emite el token)
+	aud       string                 // Audience (para quién es el token)
+	leeway    time.Duration          // Margen tolerado por desfase de relojes
+}
+
+func NewJWTAuthenticator(secret, iss, aud string, leeway time.Duration) *JWTAuthenticator {
+	return &JWTAuthenticator{
+		secret: secret,
+		keys:   make(map[string]*SigningKey),
+		iss:    iss,
+		aud:    aud,
+		leeway: leeway,
+	}
 }
 
-func NewJWTAuthenticator(secret, iss, aud string) *JWTAuthenticator {
-	return &JWTAuthenticator{secret: secret, iss: iss, aud: aud}
+// AddKey registra una clave asimétrica. Mantener registradas las claves
+// anteriores permite rotar sin invalidar los tokens ya emitidos.
+func (a *JWTAuthenticator) AddKey(key *SigningKey) {
+	a.keys[key.ID] = key
+}
+
+// SetActiveKey elige con qué clave registrada se firman los tokens nuevos.
+func (a *JWTAuthenticator) SetActiveKey(kid string) error {
+	key, ok := a.keys[kid]
+	if !ok {
+		return fmt.Errorf("la clave %q no está registrada", kid)
+	}
+	if !key.CanSign() {
+		return fmt.Errorf("la clave %q no incluye la parte privada", kid)
+	}
+	a.activeKID = kid
+	return nil
+}
+
+// Issuer devuelve el iss que deben llevar nuestros tokens.
+func (a *JWTAuthenticator) Issuer() string {
+	return a.iss
+}
+
+// Audience devuelve el aud que deben llevar nuestros tokens.
+func (a *JWTAuthenticator) Audience() string {
+	return a.aud
 }
 
 // GenerateToken crea un nuevo token JWT con los claims dados.
+// Usa la clave asimétrica activa (con su kid en la cabecera) o, si no hay, HS256.
 func (a *JWTAuthenticator) GenerateToken(claims jwt.Claims) (string, error) {
+	if a.activeKID != "" {
+		key := a.keys[a.activeKID]
+		token := jwt.NewWithClaims(key.Method, claims)
+		token.Header["kid"] = key.ID
+		return token.SignedString(key.private)
+	}
+
+	if a.secret == "" {
+		return "", errors.New("no hay ninguna clave configurada para firmar tokens")
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// Firmamos el token con nuestro secreto. ¡Esta es la parte crucial!
 	return token.SignedString([]byte(a.secret))
 }
 
-// ValidateToken verifica la firma y validez de un token.
+// ValidateToken verifica la firma y validez de un token: algoritmo, issuer,
+// audience y las fechas exp/nbf/iat (con el margen de desfase configurado).
 func (a *JWTAuthenticator) ValidateToken(tokenString string) (*jwt.Token, error) {
-	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+	return a.ValidateTokenForAudience(tokenString, a.aud)
+}
+
+// ValidateTokenForAudience es como ValidateToken pero exige otra audience.
+// Sirve para tokens de un solo propósito (ej. el reto de 2FA) que no deben
+// aceptarse como access tokens.
+func (a *JWTAuthenticator) ValidateTokenForAudience(tokenString, aud string) (*jwt.Token, error) {
+	token, err := jwt.Parse(tokenString, a.keyFunc,
+		jwt.WithValidMethods(a.validMethods()),
+		jwt.WithIssuer(a.iss),
+		jwt.WithAudience(aud),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(a.leeway),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// La librería valida iat solo si existe; nosotros lo exigimos siempre.
+	iat, err := token.Claims.GetIssuedAt()
+	if err != nil {
+		return nil, err
+	}
+	if iat == nil {
+		return nil, ErrMissingIssuedAt
+	}
+
+	return token, nil
+}
+
+// JWKS devuelve las claves públicas de todas las claves asimétricas registradas.
+func (a *JWTAuthenticator) JWKS() JWKS {
+	kids := make([]string, 0, len(a.keys))
+	for kid := range a.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(kids))}
+	for _, kid := range kids {
+		jwks.Keys = append(jwks.Keys, a.keys[kid].JWK())
+	}
+	return jwks
+}
+
+// keyFunc elige la clave de verificación según el kid y el algoritmo del token.
+func (a *JWTAuthenticator) keyFunc(token *jwt.Token) (interface{}, error) {
+	if kid, ok := token.Header["kid"].(string); ok {
+		key, ok := a.keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, fmt.Errorf("método de firma inesperado: %v", token.Header["alg"])
 		}
-		return []byte(a.secret), nil
-	})
+		return key.public, nil
+	}
+
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || a.secret == "" {
+		return nil, fmt.Errorf("método de firma inesperado: %v", token.Header["alg"])
+	}
+	return []byte(a.secret), nil
+}
+
+func (a *JWTAuthenticator) validMethods() []string {
+	methods := []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+	if a.secret != "" {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	return methods
 }
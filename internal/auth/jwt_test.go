@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestValidateToken(t *testing.T) {
+	const (
+		iss    = "gophersocial"
+		aud    = "gophersocial"
+		leeway = 30 * time.Second
+	)
+	a := NewJWTAuthenticator("test-secret", iss, aud, leeway)
+	now := time.Now()
+
+	// validClaims devuelve unos claims aceptables; cada caso cambia uno.
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub": "1",
+			"iss": iss,
+			"aud": aud,
+			"exp": now.Add(time.Minute).Unix(),
+			"nbf": now.Unix(),
+			"iat": now.Unix(),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(jwt.MapClaims)
+		secret  string // vacío = el mismo secreto que el autenticador
+		wantErr error  // nil = el token es válido
+	}{
+		{name: "valid token", modify: func(c jwt.MapClaims) {}},
+		{name: "wrong issuer", modify: func(c jwt.MapClaims) { c["iss"] = "otro" }, wantErr: jwt.ErrTokenInvalidIssuer},
+		{name: "missing issuer", modify: func(c jwt.MapClaims) { delete(c, "iss") }, wantErr: jwt.ErrTokenRequiredClaimMissing},
+		{name: "wrong audience", modify: func(c jwt.MapClaims) { c["aud"] = "otro" }, wantErr: jwt.ErrTokenInvalidAudience},
+		{name: "missing audience", modify: func(c jwt.MapClaims) { delete(c, "aud") }, wantErr: jwt.ErrTokenRequiredClaimMissing},
+		{name: "expired", modify: func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Minute).Unix() }, wantErr: jwt.ErrTokenExpired},
+		{name: "missing exp", modify: func(c jwt.MapClaims) { delete(c, "exp") }, wantErr: jwt.ErrTokenRequiredClaimMissing},
+		{name: "nbf in the future", modify: func(c jwt.MapClaims) { c["nbf"] = now.Add(time.Minute).Unix() }, wantErr: jwt.ErrTokenNotValidYet},
+		{name: "iat in the future", modify: func(c jwt.MapClaims) { c["iat"] = now.Add(time.Minute).Unix() }, wantErr: jwt.ErrTokenUsedBeforeIssued},
+		{name: "missing iat", modify: func(c jwt.MapClaims) { delete(c, "iat") }, wantErr: ErrMissingIssuedAt},
+		{name: "wrong signature", modify: func(c jwt.MapClaims) {}, secret: "otro-secreto", wantErr: jwt.ErrTokenSignatureInvalid},
+
+		// Margen de desfase: dentro del leeway se acepta, justo fuera se rechaza.
+		{name: "expired within leeway", modify: func(c jwt.MapClaims) { c["exp"] = now.Add(-leeway + 5*time.Second).Unix() }},
+		{name: "expired just past leeway", modify: func(c jwt.MapClaims) { c["exp"] = now.Add(-leeway - 5*time.Second).Unix() }, wantErr: jwt.ErrTokenExpired},
+		{name: "nbf within leeway", modify: func(c jwt.MapClaims) { c["nbf"] = now.Add(leeway - 5*time.Second).Unix() }},
+		{name: "nbf just past leeway", modify: func(c jwt.MapClaims) { c["nbf"] = now.Add(leeway + 5*time.Second).Unix() }, wantErr: jwt.ErrTokenNotValidYet},
+		{name: "iat within leeway", modify: func(c jwt.MapClaims) { c["iat"] = now.Add(leeway - 5*time.Second).Unix() }},
+		{name: "iat just past leeway", modify: func(c jwt.MapClaims) { c["iat"] = now.Add(leeway + 5*time.Second).Unix() }, wantErr: jwt.ErrTokenUsedBeforeIssued},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.modify(claims)
+
+			signer := a
+			if tt.secret != "" {
+				signer = NewJWTAuthenticator(tt.secret, iss, aud, leeway)
+			}
+			tokenString, err := signer.GenerateToken(claims)
+			if err != nil {
+				t.Fatalf("GenerateToken: %v", err)
+			}
+
+			_, err = a.ValidateToken(tokenString)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("expected a valid token, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateTokenForAudience(t *testing.T) {
+	a := NewJWTAuthenticator("test-secret", "gophersocial", "gophersocial", 0)
+	now := time.Now()
+
+	tokenString, err := a.GenerateToken(jwt.MapClaims{
+		"iss": "gophersocial",
+		"aud": "gophersocial-2fa",
+		"exp": now.Add(time.Minute).Unix(),
+		"iat": now.Unix(),
+	})
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	// Un token de un solo propósito no sirve como access token...
+	if _, err := a.ValidateToken(tokenString); !errors.Is(err, jwt.ErrTokenInvalidAudience) {
+		t.Fatalf("expected %v, got %v", jwt.ErrTokenInvalidAudience, err)
+	}
+	// ...pero sí para su propia audience.
+	if _, err := a.ValidateTokenForAudience(tokenString, "gophersocial-2fa"); err != nil {
+		t.Fatalf("expected a valid token, got %v", err)
+	}
+}
@@ -0,0 +1,308 @@
+// internal/auth/oidc.go
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrOIDCDiscovery     = errors.New("oidc: discovery failed")
+	ErrOIDCExchange      = errors.New("oidc: code exchange failed")
+	ErrOIDCInvalidToken  = errors.New("oidc: invalid id_token")
+	ErrOIDCNonceMismatch = errors.New("oidc: nonce mismatch")
+)
+
+// jwksRefetchInterval es el tiempo mínimo entre dos descargas del JWKS.
+const jwksRefetchInterval = time.Minute
+
+// OIDCConfig son los datos de nuestra aplicación registrada en el proveedor.
+type OIDCConfig struct {
+	Issuer       string // URL base del proveedor (se le añade /.well-known/openid-configuration)
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// OIDCIdentity es lo que sabemos del usuario tras verificar su id_token.
+type OIDCIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCProvider implementa el flujo authorization code + PKCE contra un proveedor
+// OpenID Connect. Solo usa HTTP estándar, así que en tests basta con apuntarlo
+// a un httptest.Server que haga de proveedor.
+type OIDCProvider struct {
+	cfg      OIDCConfig
+	client   *http.Client
+	metadata oidcMetadata
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey // Claves del proveedor por kid
+	fetchedAt time.Time                   // Última descarga del JWKS
+	fetching  chan struct{}               // Se cierra al terminar la descarga en curso
+}
+
+// NewOIDCProvider descubre los endpoints del proveedor. Si client es nil usa
+// uno con timeout razonable.
+func NewOIDCProvider(ctx context.Context, cfg OIDCConfig, client *http.Client) (*OIDCProvider, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	p := &OIDCProvider{cfg: cfg, client: client, keys: make(map[string]crypto.PublicKey)}
+
+	discoveryURL := strings.TrimRight(cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, discoveryURL, &p.metadata); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCDiscovery, err)
+	}
+	if p.metadata.Issuer != cfg.Issuer {
+		return nil, fmt.Errorf("%w: el issuer %q no coincide con %q", ErrOIDCDiscovery, p.metadata.Issuer, cfg.Issuer)
+	}
+
+	return p, nil
+}
+
+// AuthCodeURL devuelve la URL del proveedor a la que redirigimos al usuario.
+func (p *OIDCProvider) AuthCodeURL(state, nonce, codeChallenge string) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.cfg.ClientID)
+	params.Set("redirect_uri", p.cfg.RedirectURL)
+	params.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(p.metadata.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.metadata.AuthorizationEndpoint + sep + params.Encode()
+}
+
+// Exchange canjea el código de autorización por tokens y devuelve la identidad
+// del id_token, comprobando que el nonce sea el que enviamos.
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OIDCIdentity, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCExchange, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: el proveedor respondió %d", ErrOIDCExchange, res.StatusCode)
+	}
+
+	var body struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCExchange, err)
+	}
+	if body.IDToken == "" {
+		return nil, fmt.Errorf("%w: la respuesta no incluye id_token", ErrOIDCExchange)
+	}
+
+	return p.verifyIDToken(ctx, body.IDToken, nonce)
+}
+
+// verifyIDToken valida firma, issuer, audience, fechas y nonce del id_token.
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, raw, nonce string) (*OIDCIdentity, error) {
+	token, err := jwt.Parse(raw, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+		jwt.WithIssuer(p.metadata.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCInvalidToken, err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrOIDCInvalidToken
+	}
+
+	if got, _ := claims["nonce"].(string); got == "" || got != nonce {
+		return nil, ErrOIDCNonceMismatch
+	}
+
+	identity := &OIDCIdentity{}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	identity.Name, _ = claims["name"].(string)
+	// Algunos proveedores envían email_verified como string.
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = v
+	case string:
+		identity.EmailVerified = v == "true"
+	}
+
+	if identity.Subject == "" {
+		return nil, fmt.Errorf("%w: falta el claim sub", ErrOIDCInvalidToken)
+	}
+	return identity, nil
+}
+
+// key devuelve la clave pública del proveedor para ese kid. Si no la conocemos,
+// volvemos a descargar el JWKS por si el proveedor rotó sus claves, pero como
+// mucho una vez cada jwksRefetchInterval: el kid lo elige quien envía el token.
+// La descarga se hace sin el mutex; las peticiones que llegan mientras tanto
+// esperan a que termine.
+func (p *OIDCProvider) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	if key, ok := p.keys[kid]; ok {
+		p.mu.Unlock()
+		return key, nil
+	}
+	if done := p.fetching; done != nil {
+		p.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return p.cachedKey(kid)
+	}
+	if time.Since(p.fetchedAt) < jwksRefetchInterval {
+		p.mu.Unlock()
+		return nil, ErrUnknownKey
+	}
+	done := make(chan struct{})
+	p.fetching, p.fetchedAt = done, time.Now()
+	p.mu.Unlock()
+
+	keys, err := p.fetchKeys(ctx)
+
+	p.mu.Lock()
+	if err == nil {
+		p.keys = keys
+	}
+	p.fetching = nil
+	close(done)
+	p.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	return p.cachedKey(kid)
+}
+
+func (p *OIDCProvider) cachedKey(kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// fetchKeys descarga el JWKS del proveedor y devuelve sus claves de firma por kid.
+func (p *OIDCProvider) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	var jwks JWKS
+	if err := p.getJSON(ctx, p.metadata.JWKSURI, &jwks); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue // Ignoramos los tipos de clave que no soportamos
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, target string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s respondió %d", target, res.StatusCode)
+	}
+	return json.NewDecoder(res.Body).Decode(dst)
+}
+
+// NewPKCE genera un code_verifier aleatorio y su code_challenge S256 (RFC 7636).
+func NewPKCE() (verifier, challenge string, err error) {
+	verifier, err = RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// RandomString devuelve n bytes aleatorios codificados en base64 URL-safe.
+// Lo usamos para state, nonce y code_verifier.
+func RandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,315 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "gophersocial"
+	testClientSecret = "client-secret"
+	testRedirectURL  = "http://localhost:8080/v1/authentication/oidc/callback"
+	testCode         = "auth-code"
+)
+
+// fakeOIDCServer es un proveedor OpenID Connect mínimo: discovery, JWKS y un
+// token endpoint que comprueba el código y el PKCE antes de emitir el id_token.
+type fakeOIDCServer struct {
+	*httptest.Server
+	key *SigningKey // Clave publicada en el JWKS
+
+	jwksRequests int // Veces que se descargó el JWKS
+
+	challenge string        // code_challenge recibido en la autorización
+	signer    *SigningKey   // Clave con la que se firma el id_token
+	claims    jwt.MapClaims // Claims del id_token
+}
+
+func newFakeOIDCServer(t *testing.T) *fakeOIDCServer {
+	t.Helper()
+
+	f := &fakeOIDCServer{key: newTestRSAKey(t, "provider-key")}
+	f.signer = f.key
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcMetadata{
+			Issuer:                f.URL,
+			AuthorizationEndpoint: f.URL + "/authorize",
+			TokenEndpoint:         f.URL + "/token",
+			JWKSURI:               f.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		f.jwksRequests++
+		json.NewEncoder(w).Encode(JWKS{Keys: []JWK{f.key.JWK()}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != testClientID || secret != testClientSecret {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+			return
+		}
+
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("grant_type") != "authorization_code" ||
+			r.PostForm.Get("code") != testCode ||
+			r.PostForm.Get("redirect_uri") != testRedirectURL ||
+			base64.RawURLEncoding.EncodeToString(sum[:]) != f.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		token := jwt.NewWithClaims(f.signer.Method, f.claims)
+		token.Header["kid"] = f.signer.ID
+		idToken, err := token.SignedString(f.signer.private)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+	})
+
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func newTestRSAKey(t *testing.T, kid string) *SigningKey {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &SigningKey{ID: kid, Method: jwt.SigningMethodRS256, private: private, public: &private.PublicKey}
+}
+
+func newTestOIDCProvider(t *testing.T, f *fakeOIDCServer) *OIDCProvider {
+	t.Helper()
+	p, err := NewOIDCProvider(t.Context(), OIDCConfig{
+		Issuer:       f.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+	}, f.Client())
+	if err != nil {
+		t.Fatalf("NewOIDCProvider: %v", err)
+	}
+	return p
+}
+
+func TestOIDCDiscovery(t *testing.T) {
+	f := newFakeOIDCServer(t)
+	p := newTestOIDCProvider(t, f)
+
+	authURL, err := url.Parse(p.AuthCodeURL("the-state", "the-nonce", "the-challenge"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := authURL.Scheme + "://" + authURL.Host + authURL.Path; got != f.URL+"/authorize" {
+		t.Errorf("expected the authorization endpoint from discovery, got %q", got)
+	}
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"state":                 "the-state",
+		"nonce":                 "the-nonce",
+		"code_challenge":        "the-challenge",
+		"code_challenge_method": "S256",
+	}
+	for param, value := range want {
+		if got := authURL.Query().Get(param); got != value {
+			t.Errorf("%s = %q, want %q", param, got, value)
+		}
+	}
+
+	// El issuer anunciado debe coincidir con el configurado.
+	_, err = NewOIDCProvider(t.Context(), OIDCConfig{Issuer: f.URL + "/otro", ClientID: testClientID}, f.Client())
+	if !errors.Is(err, ErrOIDCDiscovery) {
+		t.Errorf("expected %v for an unknown issuer path, got %v", ErrOIDCDiscovery, err)
+	}
+}
+
+func TestOIDCExchange(t *testing.T) {
+	const nonce = "the-nonce"
+
+	tests := []struct {
+		name string
+		// modify ajusta el proveedor o los parámetros antes del canje.
+		modify       func(t *testing.T, f *fakeOIDCServer, verifier, nonce *string)
+		wantErr      error
+		wantVerified bool
+	}{
+		{
+			name:         "valid exchange",
+			modify:       func(*testing.T, *fakeOIDCServer, *string, *string) {},
+			wantVerified: true,
+		},
+		{
+			name: "wrong PKCE verifier",
+			modify: func(_ *testing.T, _ *fakeOIDCServer, verifier, _ *string) {
+				*verifier = "otro-verifier"
+			},
+			wantErr: ErrOIDCExchange,
+		},
+		{
+			name: "nonce mismatch",
+			modify: func(_ *testing.T, _ *fakeOIDCServer, _, nonce *string) {
+				*nonce = "otro-nonce"
+			},
+			wantErr: ErrOIDCNonceMismatch,
+		},
+		{
+			name: "bad signature",
+			modify: func(t *testing.T, f *fakeOIDCServer, _, _ *string) {
+				// Mismo kid, pero firmado con una clave que el proveedor no publica.
+				f.signer = newTestRSAKey(t, f.key.ID)
+			},
+			wantErr: ErrOIDCInvalidToken,
+		},
+		{
+			name: "wrong audience",
+			modify: func(_ *testing.T, f *fakeOIDCServer, _, _ *string) {
+				f.claims["aud"] = "otra-app"
+			},
+			wantErr: ErrOIDCInvalidToken,
+		},
+		{
+			name: "expired id_token",
+			modify: func(_ *testing.T, f *fakeOIDCServer, _, _ *string) {
+				f.claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			},
+			wantErr: ErrOIDCInvalidToken,
+		},
+		{
+			name: "email not verified",
+			modify: func(_ *testing.T, f *fakeOIDCServer, _, _ *string) {
+				f.claims["email_verified"] = false
+			},
+			wantVerified: false,
+		},
+		{
+			name: "email not verified as string",
+			modify: func(_ *testing.T, f *fakeOIDCServer, _, _ *string) {
+				f.claims["email_verified"] = "false"
+			},
+			wantVerified: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeOIDCServer(t)
+			p := newTestOIDCProvider(t, f)
+
+			verifier, challenge, err := NewPKCE()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// El usuario pasa por la URL de autorización: el proveedor se queda con el challenge.
+			authURL, err := url.Parse(p.AuthCodeURL("the-state", nonce, challenge))
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.challenge = authURL.Query().Get("code_challenge")
+			f.claims = jwt.MapClaims{
+				"iss":            f.URL,
+				"aud":            testClientID,
+				"sub":            "external-123",
+				"email":          "gopher@example.com",
+				"email_verified": true,
+				"name":           "Gopher",
+				"nonce":          authURL.Query().Get("nonce"),
+				"iat":            time.Now().Unix(),
+				"exp":            time.Now().Add(time.Minute).Unix(),
+			}
+
+			expectedNonce := nonce
+			tt.modify(t, f, &verifier, &expectedNonce)
+
+			identity, err := p.Exchange(t.Context(), testCode, verifier, expectedNonce)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+
+			if identity.Subject != "external-123" || identity.Email != "gopher@example.com" || identity.Name != "Gopher" {
+				t.Errorf("unexpected identity %+v", identity)
+			}
+			if identity.EmailVerified != tt.wantVerified {
+				t.Errorf("EmailVerified = %v, want %v", identity.EmailVerified, tt.wantVerified)
+			}
+		})
+	}
+}
+
+func TestOIDCUnknownKeyRefetch(t *testing.T) {
+	f := newFakeOIDCServer(t)
+	p := newTestOIDCProvider(t, f)
+
+	idToken := func(signer *SigningKey) string {
+		t.Helper()
+		token := jwt.NewWithClaims(signer.Method, jwt.MapClaims{
+			"iss":   f.URL,
+			"aud":   testClientID,
+			"sub":   "external-123",
+			"nonce": "the-nonce",
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Minute).Unix(),
+		})
+		token.Header["kid"] = signer.ID
+		raw, err := token.SignedString(signer.private)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+
+	if _, err := p.verifyIDToken(t.Context(), idToken(f.key), "the-nonce"); err != nil {
+		t.Fatalf("verifyIDToken: %v", err)
+	}
+
+	// Tokens con kids inventados no deben provocar una descarga del JWKS cada uno.
+	for _, kid := range []string{"unknown-1", "unknown-2"} {
+		_, err := p.verifyIDToken(t.Context(), idToken(newTestRSAKey(t, kid)), "the-nonce")
+		if !errors.Is(err, ErrOIDCInvalidToken) {
+			t.Fatalf("expected %v, got %v", ErrOIDCInvalidToken, err)
+		}
+	}
+	if f.jwksRequests != 1 {
+		t.Errorf("JWKS fetched %d times, want 1", f.jwksRequests)
+	}
+
+	// Pasado el intervalo, un kid desconocido vuelve a descargarlo (rotación de claves).
+	p.fetchedAt = time.Now().Add(-jwksRefetchInterval)
+	f.key = newTestRSAKey(t, "rotated-key")
+	if _, err := p.verifyIDToken(t.Context(), idToken(f.key), "the-nonce"); err != nil {
+		t.Fatalf("verifyIDToken after rotation: %v", err)
+	}
+	if f.jwksRequests != 2 {
+		t.Errorf("JWKS fetched %d times, want 2", f.jwksRequests)
+	}
+}
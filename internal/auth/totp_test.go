@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1_700_000_010, 0)
+	counter := now.Unix() / int64(totpPeriod.Seconds())
+
+	tests := []struct {
+		name        string
+		code        string
+		wantOK      bool
+		wantCounter int64
+	}{
+		{name: "current period", code: totpCode(key, uint64(counter)), wantOK: true, wantCounter: counter},
+		{name: "previous period", code: totpCode(key, uint64(counter-1)), wantOK: true, wantCounter: counter - 1},
+		{name: "next period", code: totpCode(key, uint64(counter+1)), wantOK: true, wantCounter: counter + 1},
+		{name: "outside skew", code: totpCode(key, uint64(counter-2)), wantOK: false},
+		{name: "wrong length", code: "12345", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ValidateTOTP(secret, tt.code, now)
+			if ok != tt.wantOK {
+				t.Fatalf("expected ok=%v, got %v", tt.wantOK, ok)
+			}
+			if ok && got != tt.wantCounter {
+				t.Fatalf("expected counter %d, got %d", tt.wantCounter, got)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ package env
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 // GetString lee una variable de entorno como string.
@@ -44,3 +45,17 @@ func GetBool(key string, fallback bool) bool {
 	}
 	return boolVal
 }
+
+// GetDuration lee una variable de entorno como duración (ej. "30s", "15m").
+func GetDuration(key string, fallback time.Duration) time.Duration {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(val)
+	if err != nil {
+		return fallback
+	}
+	return duration
+}
@@ -16,26 +16,16 @@ type MailtrapClient struct {
 }
 
 func (m MailtrapClient) Send(templateFile, _, email string, data any) (int, error) {
-	tmpl, err := template.New("email").ParseFS(templateFS, "templates/"+templateFile)
+	subject, body, err := render(templateFile, data)
 	if err != nil {
 		return 0, err
 	}
 
-	subject := new(bytes.Buffer)
-	if err = tmpl.ExecuteTemplate(subject, "subject", data); err != nil {
-		return 0, err
-	}
-
-	body := new(bytes.Buffer)
-	if err = tmpl.ExecuteTemplate(body, "body", data); err != nil {
-		return 0, err
-	}
-
 	msg := gomail.NewMessage()
 	msg.SetHeader("From", m.From)
 	msg.SetHeader("To", email)
-	msg.SetHeader("Subject", subject.String())
-	msg.SetBody("text/html", body.String())
+	msg.SetHeader("Subject", subject)
+	msg.SetBody("text/html", body)
 
 	d := gomail.NewDialer(m.Host, m.Port, m.Username, m.Password)
 
@@ -45,3 +35,23 @@ func (m MailtrapClient) Send(templateFile, _, email string, data any) (int, erro
 
 	return 200, nil
 }
+
+// render ejecuta los bloques "subject" y "body" de una plantilla.
+func render(templateFile string, data any) (string, string, error) {
+	tmpl, err := template.New("email").ParseFS(templateFS, "templates/"+templateFile)
+	if err != nil {
+		return "", "", err
+	}
+
+	subject := new(bytes.Buffer)
+	if err = tmpl.ExecuteTemplate(subject, "subject", data); err != nil {
+		return "", "", err
+	}
+
+	body := new(bytes.Buffer)
+	if err = tmpl.ExecuteTemplate(body, "body", data); err != nil {
+		return "", "", err
+	}
+
+	return subject.String(), body.String(), nil
+}
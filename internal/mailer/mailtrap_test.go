@@ -0,0 +1,38 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderLinks(t *testing.T) {
+	const link = "https://app.example.com/confirm/a%2Fb%20c"
+
+	tests := []struct {
+		template string
+		data     map[string]any
+	}{
+		{template: "user_invitation.tmpl", data: map[string]any{"Username": "gopher", "ActivationURL": link}},
+		{template: "password_reset.tmpl", data: map[string]any{"Username": "gopher", "ResetURL": link, "ExpiresIn": "1h0m0s"}},
+		{template: "email_change.tmpl", data: map[string]any{"Username": "gopher", "ConfirmURL": link, "ExpiresIn": "24h0m0s"}},
+		{template: "email_change_taken.tmpl", data: map[string]any{"ForgotPasswordURL": link}},
+		{template: "email_change_notice.tmpl", data: map[string]any{"Username": "gopher", "NewEmail": "new@example.com", "ForgotPasswordURL": link}},
+		{template: "account_locked.tmpl", data: map[string]any{"Username": "gopher", "IP": "127.0.0.1", "LockedFor": "15m0s", "ForgotPasswordURL": link}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			subject, body, err := render(tt.template, tt.data)
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			if strings.TrimSpace(subject) == "" {
+				t.Error("expected a subject")
+			}
+			// El enlace debe llegar tal cual, sin volver a escaparse.
+			if !strings.Contains(body, link) {
+				t.Errorf("expected the body to contain %q, got:\n%s", link, body)
+			}
+		})
+	}
+}
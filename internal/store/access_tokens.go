@@ -0,0 +1,139 @@
+// internal/store/access_tokens.go
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PersonalAccessToken es un token con nombre, scopes y caducidad pensado para scripts y bots.
+type PersonalAccessToken struct {
+	ID         int64    `json:"id"`
+	UserID     int64    `json:"-"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  string   `json:"expires_at"`
+	LastUsedAt *string  `json:"last_used_at"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+type AccessTokenStore struct {
+	db *sql.DB
+}
+
+// Create guarda un token nuevo a partir de su hash.
+func (s *AccessTokenStore) Create(ctx context.Context, token *PersonalAccessToken, tokenHash []byte, exp time.Duration) error {
+	query := `
+		INSERT INTO personal_access_tokens (user_id, name, token, scopes, expiry)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, expiry, created_at`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return s.db.QueryRowContext(ctx, query, token.UserID, token.Name, tokenHash, pq.Array(token.Scopes), time.Now().Add(exp)).Scan(
+		&token.ID, &token.ExpiresAt, &token.CreatedAt,
+	)
+}
+
+// GetByHash busca un token vigente a partir de su hash.
+func (s *AccessTokenStore) GetByHash(ctx context.Context, tokenHash []byte) (*PersonalAccessToken, error) {
+	query := `
+		SELECT id, user_id, name, scopes, expiry, last_used_at, created_at
+		FROM personal_access_tokens
+		WHERE token = $1 AND expiry > $2`
+
+	var token PersonalAccessToken
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query, tokenHash, time.Now()).Scan(
+		&token.ID, &token.UserID, &token.Name, pq.Array(&token.Scopes), &token.ExpiresAt, &token.LastUsedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetByUserID lista los tokens del usuario (incluidos los caducados, para que pueda borrarlos).
+func (s *AccessTokenStore) GetByUserID(ctx context.Context, userID int64) ([]PersonalAccessToken, error) {
+	query := `
+		SELECT id, user_id, name, scopes, expiry, last_used_at, created_at
+		FROM personal_access_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []PersonalAccessToken{}
+	for rows.Next() {
+		var t PersonalAccessToken
+		err := rows.Scan(&t.ID, &t.UserID, &t.Name, pq.Array(&t.Scopes), &t.ExpiresAt, &t.LastUsedAt, &t.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
+}
+
+// Touch actualiza la fecha de último uso. Solo escribe una vez por minuto como mucho.
+func (s *AccessTokenStore) Touch(ctx context.Context, id int64) error {
+	query := `
+		UPDATE personal_access_tokens SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// Delete revoca un token del usuario.
+func (s *AccessTokenStore) Delete(ctx context.Context, userID, id int64) error {
+	query := `DELETE FROM personal_access_tokens WHERE id = $1 AND user_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// deleteUserAccessTokens revoca todos los tokens personales del usuario. Se usa
+// al suspenderle, al restablecer su contraseña y al cerrar todas sus sesiones:
+// un token personal no es una sesión, pero da el mismo acceso.
+func deleteUserAccessTokens(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `DELETE FROM personal_access_tokens WHERE user_id = $1`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	_, err := tx.ExecContext(ctx, query, userID)
+	return err
+}
@@ -0,0 +1,98 @@
+// internal/store/audit_logs.go
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// Acciones que quedan registradas en el log de auditoría.
+const (
+	AuditUserRoleUpdated = "user.role_updated"
+	AuditUserBanned      = "user.banned"
+	AuditUserUnbanned    = "user.unbanned"
+)
+
+// AuditLog registra una acción administrativa: quién hizo qué sobre qué recurso.
+type AuditLog struct {
+	ID         int64          `json:"id"`
+	ActorID    *int64         `json:"actor_id"` // nil si el actor ya no existe
+	Action     string         `json:"action"`
+	TargetType string         `json:"target_type"`
+	TargetID   int64          `json:"target_id"`
+	Metadata   map[string]any `json:"metadata"`
+	CreatedAt  string         `json:"created_at"`
+}
+
+type AuditLogStore struct {
+	db *sql.DB
+}
+
+// Create guarda una entrada del log.
+func (s *AuditLogStore) Create(ctx context.Context, log *AuditLog) error {
+	if log.Metadata == nil {
+		log.Metadata = map[string]any{}
+	}
+	metadata, err := json.Marshal(log.Metadata)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_logs (actor_id, action, target_type, target_id, metadata)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return s.db.QueryRowContext(ctx, query, log.ActorID, log.Action, log.TargetType, log.TargetID, metadata).Scan(&log.ID, &log.CreatedAt)
+}
+
+// GetAll lista el log paginado por (created_at, id). Search filtra por acción exacta.
+func (s *AuditLogStore) GetAll(ctx context.Context, q PaginatedQuery) ([]AuditLog, string, error) {
+	query := fmt.Sprintf(`
+		SELECT id, actor_id, action, target_type, target_id, metadata, created_at
+		FROM audit_logs
+		WHERE ($1::timestamptz IS NULL OR (created_at, id) %s ($1::timestamptz, $2::bigint))
+			AND ($3::timestamptz IS NULL OR created_at >= $3)
+			AND ($4::timestamptz IS NULL OR created_at <= $4)
+			AND ($5 = '' OR action = $5)
+		ORDER BY created_at %s, id %s
+		LIMIT $6`, q.cursorOperator(), q.direction(), q.direction())
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	cursorTime, cursorID := q.cursorArgs()
+	rows, err := s.db.QueryContext(ctx, query, cursorTime, cursorID, timeArg(q.Since), timeArg(q.Until), q.Search, q.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	logs := []AuditLog{}
+	for rows.Next() {
+		var (
+			log      AuditLog
+			metadata []byte
+		)
+		err := rows.Scan(&log.ID, &log.ActorID, &log.Action, &log.TargetType, &log.TargetID, &metadata, &log.CreatedAt)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := json.Unmarshal(metadata, &log.Metadata); err != nil {
+			return nil, "", err
+		}
+		logs = append(logs, log)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	return paginate(logs, q.Limit, func(l AuditLog) (Cursor, error) {
+		return newCursor(l.CreatedAt, l.ID)
+	})
+}
@@ -0,0 +1,165 @@
+// internal/store/blocks.go
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrBlocked indica que uno de los dos usuarios bloqueó al otro.
+	ErrBlocked = errors.New("one of the users has blocked the other")
+	// ErrSelfBlock indica que un usuario intentó bloquearse o silenciarse a sí mismo.
+	ErrSelfBlock = errors.New("a user cannot block or mute themselves")
+)
+
+// blockedBetween es la condición "a y b tienen un bloqueo en cualquier dirección".
+// Recibe las dos expresiones SQL a comparar (columnas o parámetros).
+func blockedBetween(a, b string) string {
+	return fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM user_blocks ub
+		WHERE (ub.blocker_id = %[1]s AND ub.blocked_id = %[2]s)
+			OR (ub.blocker_id = %[2]s AND ub.blocked_id = %[1]s)
+	)`, a, b)
+}
+
+// UserSummary es un usuario dentro de un listado (bloqueados, silenciados...).
+// CreatedAt es cuándo se creó la relación, no la cuenta.
+type UserSummary struct {
+	ID          int64  `json:"id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	AvatarURL   string `json:"avatar_url"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type BlockStore struct {
+	db *sql.DB
+}
+
+// Block hace que blockerID bloquee a blockedID y elimina los seguimientos y
+// las solicitudes de seguimiento entre ambos en las dos direcciones.
+func (s *BlockStore) Block(ctx context.Context, blockerID, blockedID int64) error {
+	if blockerID == blockedID {
+		return ErrSelfBlock
+	}
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `INSERT INTO user_blocks (blocker_id, blocked_id) VALUES ($1, $2)`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		if _, err := tx.ExecContext(ctx, query, blockerID, blockedID); err != nil {
+			if pqErr, ok := err.(*pq.Error); ok {
+				switch pqErr.Code {
+				case "23505":
+					return ErrConflict
+				case "23503":
+					return ErrNotFound
+				}
+			}
+			return err
+		}
+
+		query = `
+			DELETE FROM followers
+			WHERE (user_id = $1 AND follower_id = $2) OR (user_id = $2 AND follower_id = $1)`
+
+		if _, err := tx.ExecContext(ctx, query, blockerID, blockedID); err != nil {
+			return err
+		}
+
+		query = `
+			DELETE FROM follow_requests
+			WHERE (user_id = $1 AND requester_id = $2) OR (user_id = $2 AND requester_id = $1)`
+
+		_, err := tx.ExecContext(ctx, query, blockerID, blockedID)
+		return err
+	})
+}
+
+// Unblock deshace un bloqueo. Devuelve ErrNotFound si no existía.
+func (s *BlockStore) Unblock(ctx context.Context, blockerID, blockedID int64) error {
+	query := `DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// IsBlocked indica si alguno de los dos usuarios bloqueó al otro.
+func (s *BlockStore) IsBlocked(ctx context.Context, a, b int64) (bool, error) {
+	query := `SELECT ` + blockedBetween("$1", "$2")
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var blocked bool
+	err := s.db.QueryRowContext(ctx, query, a, b).Scan(&blocked)
+	return blocked, err
+}
+
+// GetBlocked lista los usuarios bloqueados por userID.
+func (s *BlockStore) GetBlocked(ctx context.Context, userID int64, q PaginatedQuery) ([]UserSummary, string, error) {
+	return listUserSummaries(ctx, s.db, "user_blocks", "blocker_id", "blocked_id", userID, q)
+}
+
+// listUserSummaries lista los usuarios de la columna target de una tabla de
+// relaciones (ej. user_blocks) para el dueño de la columna owner.
+// table, owner y target son nombres fijos del código, nunca entrada del usuario.
+func listUserSummaries(ctx context.Context, db *sql.DB, table, owner, target string, userID int64, q PaginatedQuery) ([]UserSummary, string, error) {
+	query := fmt.Sprintf(`
+		SELECT u.id, u.username, u.display_name, u.avatar_url, t.created_at
+		FROM %[1]s t
+		JOIN users u ON u.id = t.%[3]s
+		WHERE t.%[2]s = $1
+			AND ($2::timestamptz IS NULL OR (t.created_at, t.%[3]s) %[4]s ($2::timestamptz, $3::bigint))
+			AND ($4::timestamptz IS NULL OR t.created_at >= $4)
+			AND ($5::timestamptz IS NULL OR t.created_at <= $5)
+		ORDER BY t.created_at %[5]s, t.%[3]s %[5]s
+		LIMIT $6`, table, owner, target, q.cursorOperator(), q.direction())
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	cursorTime, cursorID := q.cursorArgs()
+	rows, err := db.QueryContext(ctx, query, userID, cursorTime, cursorID, timeArg(q.Since), timeArg(q.Until), q.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	users := []UserSummary{}
+	for rows.Next() {
+		var u UserSummary
+		if err := rows.Scan(&u.ID, &u.Username, &u.DisplayName, &u.AvatarURL, &u.CreatedAt); err != nil {
+			return nil, "", err
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	return paginate(users, q.Limit, func(u UserSummary) (Cursor, error) {
+		return newCursor(u.CreatedAt, u.ID)
+	})
+}
@@ -0,0 +1,55 @@
+// internal/store/cache/login_attempts.go
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// LoginAttemptStore cuenta los intentos de login fallidos y guarda los bloqueos.
+// Las claves son libres (ej. "account:<email>" o "ip:<ip>").
+type LoginAttemptStore struct {
+	rdb *redis.Client
+}
+
+// RegisterFailure suma un fallo y devuelve el total. El contador caduca tras
+// window sin fallos nuevos, así que insistir mantiene vivo el historial.
+func (s *LoginAttemptStore) RegisterFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	cacheKey := fmt.Sprintf("login-failures-%s", key)
+
+	pipe := s.rdb.TxPipeline()
+	incr := pipe.Incr(ctx, cacheKey)
+	pipe.Expire(ctx, cacheKey, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// Reset borra el contador de fallos (ej. tras un login correcto).
+func (s *LoginAttemptStore) Reset(ctx context.Context, key string) error {
+	cacheKey := fmt.Sprintf("login-failures-%s", key)
+	return s.rdb.Del(ctx, cacheKey).Err()
+}
+
+// Lock bloquea la clave durante d.
+func (s *LoginAttemptStore) Lock(ctx context.Context, key string, d time.Duration) error {
+	cacheKey := fmt.Sprintf("login-lock-%s", key)
+	return s.rdb.SetEX(ctx, cacheKey, 1, d).Err()
+}
+
+// LockedFor devuelve cuánto le queda al bloqueo de la clave (0 si no está bloqueada).
+func (s *LoginAttemptStore) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	cacheKey := fmt.Sprintf("login-lock-%s", key)
+	ttl, err := s.rdb.TTL(ctx, cacheKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil // -2: no existe; -1: sin expiración (no debería pasar)
+	}
+	return ttl, nil
+}
@@ -0,0 +1,49 @@
+// internal/store/cache/oidc.go
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// OIDCState es lo que necesitamos recordar entre la redirección al proveedor y su callback.
+type OIDCState struct {
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+type OIDCStateStore struct {
+	rdb *redis.Client
+}
+
+// Save guarda el estado de un login en curso.
+func (s *OIDCStateStore) Save(ctx context.Context, state string, data *OIDCState, ttl time.Duration) error {
+	cacheKey := fmt.Sprintf("oidc-state-%s", state)
+	js, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return s.rdb.SetEX(ctx, cacheKey, js, ttl).Err()
+}
+
+// Pop recupera y borra el estado en una sola operación, para que cada state
+// se pueda usar una única vez. Devuelve nil si no existe o ya caducó.
+func (s *OIDCStateStore) Pop(ctx context.Context, state string) (*OIDCState, error) {
+	cacheKey := fmt.Sprintf("oidc-state-%s", state)
+	data, err := s.rdb.GetDel(ctx, cacheKey).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var st OIDCState
+	if err := json.Unmarshal([]byte(data), &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
@@ -3,6 +3,7 @@ package cache
 
 import (
 	"context"
+	"time"
 
 	"GopherSocial/internal/store" // Reemplaza con tu ruta
 
@@ -10,7 +11,11 @@ import (
 )
 
 type Storage struct {
-	Users UserCacher
+	Users         UserCacher
+	Tokens        TokenDenylist
+	LoginAttempts LoginLimiter
+	OIDCStates    OIDCStateStorer
+	Suggestions   SuggestionCacher
 }
 
 // Definimos una interfaz para que nuestro código sea testeable.
@@ -20,8 +25,41 @@ type UserCacher interface {
 	Delete(context.Context, int64)
 }
 
+// TokenDenylist lleva la cuenta de los access tokens revocados antes de expirar.
+type TokenDenylist interface {
+	Revoke(context.Context, string, time.Duration) error
+	IsRevoked(context.Context, string) (bool, error)
+	RevokeSession(context.Context, string, time.Duration) error
+	IsSessionRevoked(context.Context, string) (bool, error)
+}
+
+// LoginLimiter registra los logins fallidos para aplicar bloqueos temporales.
+type LoginLimiter interface {
+	RegisterFailure(context.Context, string, time.Duration) (int64, error)
+	Reset(context.Context, string) error
+	Lock(context.Context, string, time.Duration) error
+	LockedFor(context.Context, string) (time.Duration, error)
+}
+
+// OIDCStateStorer guarda el state/nonce/PKCE de los logins OIDC en curso.
+type OIDCStateStorer interface {
+	Save(context.Context, string, *OIDCState, time.Duration) error
+	Pop(context.Context, string) (*OIDCState, error)
+}
+
+// SuggestionCacher guarda las sugerencias de seguimiento calculadas para cada usuario.
+type SuggestionCacher interface {
+	Get(context.Context, int64) ([]store.Suggestion, error)
+	Set(context.Context, int64, []store.Suggestion, time.Duration) error
+	Delete(context.Context, int64)
+}
+
 func NewRedisStorage(rdb *redis.Client) Storage {
 	return Storage{
-		Users: &UserStore{rdb: rdb},
+		Users:         &UserStore{rdb: rdb},
+		Tokens:        &TokenStore{rdb: rdb},
+		LoginAttempts: &LoginAttemptStore{rdb: rdb},
+		OIDCStates:    &OIDCStateStore{rdb: rdb},
+		Suggestions:   &SuggestionStore{rdb: rdb},
 	}
 }
@@ -0,0 +1,52 @@
+// internal/store/cache/suggestions.go
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"GopherSocial/internal/store"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// SuggestionStore guarda en Redis las sugerencias de cada usuario, que son
+// caras de calcular.
+type SuggestionStore struct {
+	rdb *redis.Client
+}
+
+// Get devuelve las sugerencias guardadas o nil si no hay (cache miss).
+func (s *SuggestionStore) Get(ctx context.Context, userID int64) ([]store.Suggestion, error) {
+	cacheKey := fmt.Sprintf("suggestions-%d", userID)
+	data, err := s.rdb.Get(ctx, cacheKey).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var suggestions []store.Suggestion
+	if err := json.Unmarshal([]byte(data), &suggestions); err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// Set guarda las sugerencias durante ttl (el intervalo de refresco).
+func (s *SuggestionStore) Set(ctx context.Context, userID int64, suggestions []store.Suggestion, ttl time.Duration) error {
+	cacheKey := fmt.Sprintf("suggestions-%d", userID)
+	js, err := json.Marshal(suggestions)
+	if err != nil {
+		return err
+	}
+	return s.rdb.SetEX(ctx, cacheKey, js, ttl).Err()
+}
+
+// Delete fuerza a recalcular las sugerencias en la próxima petición.
+func (s *SuggestionStore) Delete(ctx context.Context, userID int64) {
+	cacheKey := fmt.Sprintf("suggestions-%d", userID)
+	s.rdb.Del(ctx, cacheKey)
+}
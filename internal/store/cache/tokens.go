@@ -0,0 +1,52 @@
+// internal/store/cache/tokens.go
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// TokenStore guarda en Redis los identificadores (jti) de los access tokens revocados.
+type TokenStore struct {
+	rdb *redis.Client
+}
+
+// Revoke marca un jti como revocado. Basta con guardarlo hasta que el token
+// expire por sí mismo; después Redis lo borra automáticamente.
+func (s *TokenStore) Revoke(ctx context.Context, jti string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil // El token ya expiró, no hace falta recordarlo.
+	}
+	cacheKey := fmt.Sprintf("revoked-token-%s", jti)
+	return s.rdb.SetEX(ctx, cacheKey, 1, ttl).Err()
+}
+
+// IsRevoked indica si el jti está en la lista de tokens revocados.
+func (s *TokenStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	cacheKey := fmt.Sprintf("revoked-token-%s", jti)
+	n, err := s.rdb.Exists(ctx, cacheKey).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// RevokeSession invalida los access tokens emitidos para una sesión (claim "sid").
+// Como con los jti, basta con recordarla durante la vida de un access token.
+func (s *TokenStore) RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error {
+	cacheKey := fmt.Sprintf("revoked-session-%s", sessionID)
+	return s.rdb.SetEX(ctx, cacheKey, 1, ttl).Err()
+}
+
+// IsSessionRevoked indica si la sesión fue cerrada.
+func (s *TokenStore) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	cacheKey := fmt.Sprintf("revoked-session-%s", sessionID)
+	n, err := s.rdb.Exists(ctx, cacheKey).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -4,61 +4,217 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
 )
 
+// MaxCommentDepth es la profundidad máxima de una respuesta (0 = primer nivel).
+// Debe coincidir con el CHECK comments_depth_check.
+const MaxCommentDepth = 3
+
+// ErrCommentTooDeep indica que se intentó responder a una respuesta que ya
+// está en la profundidad máxima.
+var ErrCommentTooDeep = errors.New("comment reply depth limit reached")
+
 type Comment struct {
-	ID        int64  `json:"id"`
-	Content   string `json:"content"`
-	PostID    int64  `json:"post_id"`
-	UserID    int64  `json:"user_id"`
-	CreatedAt string `json:"created_at"`
-	User      User   `json:"user"` // Para mostrar quién comentó
+	ID           int64   `json:"id"`
+	Content      string  `json:"content"`
+	PostID       int64   `json:"post_id"`
+	UserID       int64   `json:"user_id"`
+	ParentID     *int64  `json:"parent_id"`     // nil en los comentarios de primer nivel
+	Depth        int     `json:"depth"`         // 0 en los comentarios de primer nivel
+	RepliesCount int     `json:"replies_count"` // respuestas directas visibles
+	Version      int     `json:"version"`
+	CreatedAt    string  `json:"created_at"`
+	EditedAt     *string `json:"edited_at"` // nil si nunca se editó
+	User         User    `json:"user"`      // Para mostrar quién comentó
+	// Reactions solo se rellena al listar los comentarios de una publicación.
+	Reactions *ReactionSummary `json:"reactions,omitempty"`
 }
 
 type CommentStore struct {
 	db *sql.DB
 }
 
-// Create inserta un nuevo comentario.
+// Create inserta un nuevo comentario. Si tiene ParentID es una respuesta: el
+// padre debe pertenecer a la misma publicación y no tener un bloqueo con el
+// autor (si no, ErrNotFound), y no puede superar MaxCommentDepth (ErrCommentTooDeep).
 func (s *CommentStore) Create(ctx context.Context, comment *Comment) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	if comment.ParentID == nil {
+		query := `
+			INSERT INTO comments (post_id, user_id, content)
+			VALUES ($1, $2, $3)
+			RETURNING id, created_at, depth, version`
+
+		return s.db.QueryRowContext(ctx, query, comment.PostID, comment.UserID, comment.Content).Scan(&comment.ID, &comment.CreatedAt, &comment.Depth, &comment.Version)
+	}
+
+	// La profundidad se calcula a partir del padre en la misma sentencia.
+	query := `
+		INSERT INTO comments (post_id, user_id, content, parent_id, depth)
+		SELECT p.post_id, $2, $3, p.id, p.depth + 1
+		FROM comments p
+		WHERE p.id = $4 AND p.post_id = $1 AND NOT ` + blockedBetween("$2", "p.user_id") + `
+		RETURNING id, created_at, depth, version`
+
+	err := s.db.QueryRowContext(ctx, query, comment.PostID, comment.UserID, comment.Content, *comment.ParentID).Scan(&comment.ID, &comment.CreatedAt, &comment.Depth, &comment.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		// 23514: comments_depth_check
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23514" {
+			return ErrCommentTooDeep
+		}
+		return err
+	}
+	return nil
+}
+
+// GetByPostID recupera los comentarios de primer nivel de una publicación que
+// puede ver viewerID, con su número de respuestas. Se omiten los de usuarios
+// con los que tiene un bloqueo en cualquier dirección.
+func (s *CommentStore) GetByPostID(ctx context.Context, postID, viewerID int64, q PaginatedQuery) ([]Comment, string, error) {
+	return s.list(ctx, "c.post_id = $1 AND c.parent_id IS NULL", postID, viewerID, q)
+}
+
+// GetReplies recupera las respuestas directas a commentID visibles para viewerID.
+func (s *CommentStore) GetReplies(ctx context.Context, commentID, viewerID int64, q PaginatedQuery) ([]Comment, string, error) {
+	return s.list(ctx, "c.parent_id = $1", commentID, viewerID, q)
+}
+
+// GetByID recupera un comentario de la publicación postID.
+func (s *CommentStore) GetByID(ctx context.Context, postID, id int64) (*Comment, error) {
 	query := `
-		INSERT INTO comments (post_id, user_id, content)
-		VALUES ($1, $2, $3)
-		RETURNING id, created_at`
+		SELECT c.id, c.content, c.post_id, c.user_id, c.parent_id, c.depth, c.version, c.created_at, c.edited_at, u.username, u.id
+		FROM comments c
+		JOIN users u on u.id = c.user_id
+		WHERE c.id = $1 AND c.post_id = $2`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	return s.db.QueryRowContext(ctx, query, comment.PostID, comment.UserID, comment.Content).Scan(&comment.ID, &comment.CreatedAt)
+	var c Comment
+	var parentID sql.NullInt64
+	err := s.db.QueryRowContext(ctx, query, id, postID).Scan(
+		&c.ID, &c.Content, &c.PostID, &c.UserID, &parentID, &c.Depth, &c.Version, &c.CreatedAt, &c.EditedAt, &c.User.Username, &c.User.ID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if parentID.Valid {
+		c.ParentID = &parentID.Int64
+	}
+	return &c, nil
 }
 
-// GetByPostID recupera todos los comentarios de una publicación.
-func (s *CommentStore) GetByPostID(ctx context.Context, postID int64) ([]Comment, error) {
+// Update cambia el contenido del comentario y lo marca como editado.
+// Como en PostStore.Update, devuelve ErrNotFound si la versión no coincide.
+func (s *CommentStore) Update(ctx context.Context, comment *Comment) error {
 	query := `
-		SELECT c.id, c.content, c.post_id, c.user_id, c.created_at, u.username, u.id
+		UPDATE comments SET content = $1, version = version + 1, edited_at = NOW()
+		WHERE id = $2 AND version = $3
+		RETURNING version, edited_at`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query, comment.Content, comment.ID, comment.Version).Scan(&comment.Version, &comment.EditedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound // El comentario no existe o alguien lo editó antes
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete borra un comentario junto con todas sus respuestas (ON DELETE CASCADE).
+func (s *CommentStore) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM comments WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// list es la consulta común de GetByPostID y GetReplies. filter es la condición
+// sobre c y usa $1 = id; $2 es siempre viewerID.
+func (s *CommentStore) list(ctx context.Context, filter string, id, viewerID int64, q PaginatedQuery) ([]Comment, string, error) {
+	query := fmt.Sprintf(`
+		SELECT c.id, c.content, c.post_id, c.user_id, c.parent_id, c.depth, c.version, c.created_at, c.edited_at, u.username, u.id,
+			(SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id AND NOT %s),
+			%s
 		FROM comments c
 		JOIN users u on u.id = c.user_id
-		WHERE c.post_id = $1
-		ORDER BY c.created_at DESC`
+		WHERE %s AND NOT %s
+			AND ($3::timestamptz IS NULL OR (c.created_at, c.id) %s ($3::timestamptz, $4::bigint))
+		ORDER BY c.created_at %s, c.id %s
+		LIMIT $5`,
+		blockedBetween("$2", "r.user_id"),
+		reactionColumns("comment_reactions", "comment_id", "c.id", "$2"),
+		filter, blockedBetween("$2", "c.user_id"),
+		q.cursorOperator(), q.direction(), q.direction())
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := s.db.QueryContext(ctx, query, postID)
+	cursorTime, cursorID := q.cursorArgs()
+	rows, err := s.db.QueryContext(ctx, query, id, viewerID, cursorTime, cursorID, q.Limit+1)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
 
-	var comments []Comment
+	comments := []Comment{}
 	for rows.Next() {
 		var c Comment
-		err := rows.Scan(&c.ID, &c.Content, &c.PostID, &c.UserID, &c.CreatedAt, &c.User.Username, &c.User.ID)
+		var parentID sql.NullInt64
+		var reactionCounts []byte
+		var myReaction sql.NullString
+		err := rows.Scan(
+			&c.ID, &c.Content, &c.PostID, &c.UserID, &parentID, &c.Depth, &c.Version, &c.CreatedAt, &c.EditedAt, &c.User.Username, &c.User.ID,
+			&c.RepliesCount, &reactionCounts, &myReaction,
+		)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if parentID.Valid {
+			c.ParentID = &parentID.Int64
+		}
+		c.Reactions, err = newReactionSummary(reactionCounts, myReaction)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		comments = append(comments, c)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
 
-	return comments, nil
+	return paginate(comments, q.Limit, func(c Comment) (Cursor, error) {
+		return newCursor(c.CreatedAt, c.ID)
+	})
 }
@@ -0,0 +1,88 @@
+// internal/store/email_changes.go
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CreateEmailChange guarda un token para cambiar el email del usuario a newEmail,
+// sustituyendo a las solicitudes anteriores. Devuelve ErrDuplicateEmail si el
+// email ya pertenece a otra cuenta.
+func (s *UserStore) CreateEmailChange(ctx context.Context, userID int64, newEmail string, tokenHash []byte, exp time.Duration) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		var taken bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE email = $1)`, newEmail).Scan(&taken); err != nil {
+			return err
+		}
+		if taken {
+			return ErrDuplicateEmail
+		}
+
+		if err := s.deleteEmailChanges(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		query := `INSERT INTO email_changes (token, user_id, new_email, expiry) VALUES ($1, $2, $3, $4)`
+		_, err := tx.ExecContext(ctx, query, tokenHash, userID, newEmail, time.Now().Add(exp))
+		return err
+	})
+}
+
+// ConfirmEmailChange aplica el cambio de email del token y lo consume.
+// Si otra cuenta se quedó el email mientras tanto devuelve ErrDuplicateEmail.
+func (s *UserStore) ConfirmEmailChange(ctx context.Context, tokenHash []byte) (*User, error) {
+	var user User
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		query := `
+			SELECT user_id, new_email FROM email_changes
+			WHERE token = $1 AND expiry > $2
+			FOR UPDATE`
+
+		err := tx.QueryRowContext(ctx, query, tokenHash, time.Now()).Scan(&user.ID, &user.Email)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		// La restricción UNIQUE de users.email resuelve la carrera con un
+		// registro o con otro cambio al mismo email.
+		query = `UPDATE users SET email = $1 WHERE id = $2 AND deleted_at IS NULL RETURNING username`
+		err = tx.QueryRowContext(ctx, query, user.Email, user.ID).Scan(&user.Username)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == "users_email_key" {
+				return ErrDuplicateEmail
+			}
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		return s.deleteEmailChanges(ctx, tx, user.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *UserStore) deleteEmailChanges(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `DELETE FROM email_changes WHERE user_id = $1`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	_, err := tx.ExecContext(ctx, query, userID)
+	return err
+}
@@ -0,0 +1,145 @@
+// internal/store/follow_requests.go
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// requestFollow crea una solicitud para seguir a la cuenta privada userID.
+func (s *FollowerStore) requestFollow(ctx context.Context, userID, requesterID int64) error {
+	// Si ya lo sigue no tiene sentido pedirlo otra vez, y con un bloqueo entre
+	// ellos tampoco se puede.
+	query := `
+		INSERT INTO follow_requests (user_id, requester_id)
+		SELECT $1, $2
+		WHERE NOT EXISTS (SELECT 1 FROM followers WHERE user_id = $1 AND follower_id = $2)
+			AND NOT ` + blockedBetween("$1", "$2")
+
+	res, err := s.db.ExecContext(ctx, query, userID, requesterID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code {
+			case "23505":
+				return ErrConflict
+			case "23503":
+				return ErrNotFound
+			}
+		}
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		var blocked bool
+		if err := s.db.QueryRowContext(ctx, `SELECT `+blockedBetween("$1", "$2"), userID, requesterID).Scan(&blocked); err != nil {
+			return err
+		}
+		if blocked {
+			return ErrBlocked
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+// GetRequests lista las solicitudes pendientes para seguir a userID.
+func (s *FollowerStore) GetRequests(ctx context.Context, userID int64, q PaginatedQuery) ([]UserSummary, string, error) {
+	return listUserSummaries(ctx, s.db, "follow_requests", "user_id", "requester_id", userID, q)
+}
+
+// ApproveRequest convierte la solicitud de requesterID en un seguimiento.
+// Devuelve ErrNotFound si no había solicitud.
+func (s *FollowerStore) ApproveRequest(ctx context.Context, userID, requesterID int64) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		if err := deleteFollowRequest(ctx, tx, userID, requesterID); err != nil {
+			return err
+		}
+
+		query := `INSERT INTO followers (user_id, follower_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+		if _, err := tx.ExecContext(ctx, query, userID, requesterID); err != nil {
+			return followError(err)
+		}
+		return nil
+	})
+}
+
+// RejectRequest descarta la solicitud de requesterID sin avisarle.
+func (s *FollowerStore) RejectRequest(ctx context.Context, userID, requesterID int64) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return deleteFollowRequest(ctx, tx, userID, requesterID)
+	})
+}
+
+// deleteFollowRequest borra una solicitud y devuelve ErrNotFound si no existía.
+func deleteFollowRequest(ctx context.Context, tx *sql.Tx, userID, requesterID int64) error {
+	query := `DELETE FROM follow_requests WHERE user_id = $1 AND requester_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := tx.ExecContext(ctx, query, userID, requesterID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// approveFollowRequests aprueba todas las solicitudes pendientes de userID
+// (cuando su cuenta pasa a ser pública).
+func approveFollowRequests(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `
+		INSERT INTO followers (user_id, follower_id)
+		SELECT user_id, requester_id FROM follow_requests WHERE user_id = $1
+		ON CONFLICT DO NOTHING`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, `DELETE FROM follow_requests WHERE user_id = $1`, userID)
+	return err
+}
+
+// CanSeePosts indica si viewerID puede ver las publicaciones de authorID: la
+// cuenta es pública, es la suya o es un seguidor aprobado.
+func (s *FollowerStore) CanSeePosts(ctx context.Context, authorID, viewerID int64) (bool, error) {
+	query := `
+		SELECT NOT u.is_private
+			OR u.id = $2
+			OR EXISTS (SELECT 1 FROM followers f WHERE f.user_id = u.id AND f.follower_id = $2)
+		FROM users u
+		WHERE u.id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var visible bool
+	if err := s.db.QueryRowContext(ctx, query, authorID, viewerID).Scan(&visible); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return visible, nil
+}
@@ -4,6 +4,8 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 
 	"github.com/lib/pq" // ¡Importante para manejar errores específicos de Postgres!
 )
@@ -12,37 +14,218 @@ type FollowerStore struct {
 	db *sql.DB
 }
 
-// Follow crea una nueva relación de seguimiento.
-func (s *FollowerStore) Follow(ctx context.Context, userID, followerID int64) error {
+// ErrSelfFollow indica que un usuario intentó seguirse a sí mismo.
+var ErrSelfFollow = errors.New("a user cannot follow themselves")
+
+// Follow sigue a userID o, si su cuenta es privada, le envía una solicitud
+// (devuelve pending = true). Devuelve ErrSelfFollow si ambos son el mismo
+// usuario, ErrBlocked si hay un bloqueo entre ellos, ErrNotFound si el seguido
+// no existe o no está visible (sin activar, borrado o suspendido) y ErrConflict
+// si ya lo seguía o ya había una solicitud pendiente.
+func (s *FollowerStore) Follow(ctx context.Context, userID, followerID int64) (bool, error) {
 	// userID es a quién siguen
 	// followerID es quién sigue
-	query := `
-		INSERT INTO followers (user_id, follower_id) VALUES ($1, $2)`
+	if userID == followerID {
+		return false, ErrSelfFollow
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := s.db.ExecContext(ctx, query, userID, followerID)
-	if err != nil {
-		// Verificamos si el error es porque ya existe la relación (error de clave primaria duplicada)
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-			return ErrConflict // Usamos nuestro error personalizado
+	var isPrivate, blocked bool
+	query := fmt.Sprintf(`SELECT u.is_private, %s FROM users u WHERE u.id = $1 AND %s`, blockedBetween("u.id", "$2"), visibleUser)
+	if err := s.db.QueryRowContext(ctx, query, userID, followerID).Scan(&isPrivate, &blocked); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, ErrNotFound
 		}
-		return err
+		return false, err
+	}
+	if blocked {
+		return false, ErrBlocked
+	}
+
+	if isPrivate {
+		return true, s.requestFollow(ctx, userID, followerID)
+	}
+
+	// El bloqueo se vuelve a comprobar en el propio INSERT por si alguien
+	// bloqueó al otro entre las dos sentencias.
+	query = `
+		INSERT INTO followers (user_id, follower_id)
+		SELECT $1, $2
+		WHERE NOT ` + blockedBetween("$1", "$2")
+
+	res, err := s.db.ExecContext(ctx, query, userID, followerID)
+	if err != nil {
+		return false, followError(err)
 	}
 
-	return nil
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows == 0 {
+		return false, ErrBlocked
+	}
+	return false, nil
 }
 
-// Unfollow elimina una relación de seguimiento.
+// Unfollow elimina una relación de seguimiento o cancela la solicitud
+// pendiente. Devuelve ErrNotFound si no había ninguna de las dos.
 func (s *FollowerStore) Unfollow(ctx context.Context, userID, followerID int64) error {
-	query := `
-		DELETE FROM followers
-		WHERE user_id = $1 AND follower_id = $2`
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			DELETE FROM followers
+			WHERE user_id = $1 AND follower_id = $2`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		res, err := tx.ExecContext(ctx, query, userID, followerID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows > 0 {
+			return nil
+		}
+
+		return deleteFollowRequest(ctx, tx, userID, followerID)
+	})
+}
+
+// followError traduce los errores de Postgres al insertar en followers.
+func followError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23505": // Ya existe la relación (clave primaria duplicada)
+			return ErrConflict
+		case "23503": // El usuario se borró mientras tanto
+			return ErrNotFound
+		case "23514": // followers_no_self_follow
+			return ErrSelfFollow
+		}
+	}
+	return err
+}
+
+// visibleUser es la condición para que un usuario (alias u) aparezca en
+// listados: activo, no borrado y sin suspensión en vigor.
+const visibleUser = `u.is_active AND u.deleted_at IS NULL AND (u.banned_at IS NULL OR u.banned_until <= NOW())`
+
+// FollowUser es un usuario dentro de un listado de seguidores o seguidos,
+// con su relación respecto al usuario que consulta.
+type FollowUser struct {
+	ID             int64  `json:"id"`
+	Username       string `json:"username"`
+	DisplayName    string `json:"display_name"`
+	AvatarURL      string `json:"avatar_url"`
+	FollowedAt     string `json:"followed_at"`
+	IsFollowedByMe bool   `json:"is_followed_by_me"`
+	FollowsMe      bool   `json:"follows_me"`
+}
+
+// FollowStats son los contadores de un perfil y su relación con quien lo consulta.
+type FollowStats struct {
+	FollowersCount int64 `json:"followers_count"`
+	FollowingCount int64 `json:"following_count"`
+	IsFollowedByMe bool  `json:"is_followed_by_me"`
+	FollowsMe      bool  `json:"follows_me"`
+	// FollowRequested indica que quien consulta tiene una solicitud pendiente.
+	FollowRequested bool `json:"follow_requested"`
+}
+
+// GetStats cuenta los seguidores y seguidos visibles de userID y su relación con viewerID.
+func (s *FollowerStore) GetStats(ctx context.Context, userID, viewerID int64) (*FollowStats, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			(SELECT COUNT(*) FROM followers f JOIN users u ON u.id = f.follower_id
+			 WHERE f.user_id = $1 AND %[1]s),
+			(SELECT COUNT(*) FROM followers f JOIN users u ON u.id = f.user_id
+			 WHERE f.follower_id = $1 AND %[1]s),
+			EXISTS (SELECT 1 FROM followers WHERE user_id = $1 AND follower_id = $2),
+			EXISTS (SELECT 1 FROM followers WHERE user_id = $2 AND follower_id = $1),
+			EXISTS (SELECT 1 FROM follow_requests WHERE user_id = $1 AND requester_id = $2)`, visibleUser)
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := s.db.ExecContext(ctx, query, userID, followerID)
-	return err
+	var stats FollowStats
+	err := s.db.QueryRowContext(ctx, query, userID, viewerID).Scan(
+		&stats.FollowersCount, &stats.FollowingCount, &stats.IsFollowedByMe, &stats.FollowsMe, &stats.FollowRequested,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetFollowers lista quién sigue a userID, del seguimiento más reciente al más antiguo
+// por defecto. Search filtra por username o nombre visible.
+func (s *FollowerStore) GetFollowers(ctx context.Context, userID, viewerID int64, q PaginatedQuery) ([]FollowUser, string, error) {
+	return s.list(ctx, "f.user_id = $1", "f.follower_id", userID, viewerID, q)
+}
+
+// GetFollowing lista a quién sigue userID.
+func (s *FollowerStore) GetFollowing(ctx context.Context, userID, viewerID int64, q PaginatedQuery) ([]FollowUser, string, error) {
+	return s.list(ctx, "f.follower_id = $1", "f.user_id", userID, viewerID, q)
+}
+
+// GetMutualFollowers lista los seguidores de userID a los que también sigue
+// viewerID ("seguidores en común").
+func (s *FollowerStore) GetMutualFollowers(ctx context.Context, userID, viewerID int64, q PaginatedQuery) ([]FollowUser, string, error) {
+	where := "f.user_id = $1 AND EXISTS (SELECT 1 FROM followers m WHERE m.user_id = f.follower_id AND m.follower_id = $2)"
+	return s.list(ctx, where, "f.follower_id", userID, viewerID, q)
+}
+
+// list es la consulta común a los listados de followers. where filtra las filas
+// de followers (f) y listed es la columna con el usuario que se muestra.
+// Ambos son fragmentos fijos de este archivo, nunca entrada del usuario.
+func (s *FollowerStore) list(ctx context.Context, where, listed string, userID, viewerID int64, q PaginatedQuery) ([]FollowUser, string, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			u.id, u.username, u.display_name, u.avatar_url, f.created_at,
+			EXISTS (SELECT 1 FROM followers x WHERE x.user_id = u.id AND x.follower_id = $2),
+			EXISTS (SELECT 1 FROM followers x WHERE x.user_id = $2 AND x.follower_id = u.id)
+		FROM followers f
+		JOIN users u ON u.id = %[1]s
+		WHERE %[2]s
+			AND %[3]s
+			AND ($3::timestamptz IS NULL OR (f.created_at, %[1]s) %[4]s ($3::timestamptz, $4::bigint))
+			AND ($5::timestamptz IS NULL OR f.created_at >= $5)
+			AND ($6::timestamptz IS NULL OR f.created_at <= $6)
+			AND ($7 = '' OR u.username ILIKE '%%' || $7 || '%%' OR u.display_name ILIKE '%%' || $7 || '%%')
+		ORDER BY f.created_at %[5]s, %[1]s %[5]s
+		LIMIT $8`, listed, where, visibleUser, q.cursorOperator(), q.direction())
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	cursorTime, cursorID := q.cursorArgs()
+	rows, err := s.db.QueryContext(ctx, query, userID, viewerID, cursorTime, cursorID, timeArg(q.Since), timeArg(q.Until), q.Search, q.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	users := []FollowUser{}
+	for rows.Next() {
+		var u FollowUser
+		err := rows.Scan(&u.ID, &u.Username, &u.DisplayName, &u.AvatarURL, &u.FollowedAt, &u.IsFollowedByMe, &u.FollowsMe)
+		if err != nil {
+			return nil, "", err
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	return paginate(users, q.Limit, func(u FollowUser) (Cursor, error) {
+		return newCursor(u.FollowedAt, u.ID)
+	})
 }
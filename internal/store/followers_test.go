@@ -0,0 +1,34 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestFollowError(t *testing.T) {
+	other := errors.New("connection reset")
+	notNull := &pq.Error{Code: "23502"}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "nil", err: nil, want: nil},
+		{name: "already following", err: &pq.Error{Code: "23505"}, want: ErrConflict},
+		{name: "user deleted meanwhile", err: &pq.Error{Code: "23503"}, want: ErrNotFound},
+		{name: "self follow", err: &pq.Error{Code: "23514"}, want: ErrSelfFollow},
+		{name: "other postgres error", err: notNull, want: notNull},
+		{name: "non postgres error", err: other, want: other},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := followError(tt.err); got != tt.want {
+				t.Errorf("followError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+// internal/store/login_attempts.go
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Motivos por los que puede fallar un login.
+const (
+	LoginFailureUnknownEmail = "unknown_email"
+	LoginFailureBadPassword  = "bad_password"
+	LoginFailureBad2FACode   = "bad_2fa_code"
+	LoginFailureLocked       = "locked"
+)
+
+// LoginAttempt es un registro de auditoría de un login fallido.
+type LoginAttempt struct {
+	ID        int64  `json:"id"`
+	Email     string `json:"email"`
+	UserID    *int64 `json:"user_id"`
+	IP        string `json:"ip"`
+	Reason    string `json:"reason"`
+	CreatedAt string `json:"created_at"`
+}
+
+type LoginAttemptStore struct {
+	db *sql.DB
+}
+
+// Create guarda un intento fallido.
+func (s *LoginAttemptStore) Create(ctx context.Context, attempt *LoginAttempt) error {
+	query := `
+		INSERT INTO login_attempts (email, user_id, ip, reason)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return s.db.QueryRowContext(ctx, query, attempt.Email, attempt.UserID, attempt.IP, attempt.Reason).Scan(&attempt.ID, &attempt.CreatedAt)
+}
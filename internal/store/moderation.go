@@ -0,0 +1,85 @@
+// internal/store/moderation.go
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// UpdateRole cambia el rol de un usuario.
+func (s *UserStore) UpdateRole(ctx context.Context, userID, roleID int64) error {
+	query := `UPDATE users SET role_id = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, roleID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Ban suspende al usuario hasta until (nil = indefinidamente) y, en la misma
+// transacción, cierra todas sus sesiones y borra sus tokens personales.
+func (s *UserStore) Ban(ctx context.Context, userID int64, reason string, until *time.Time) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `UPDATE users SET banned_at = NOW(), ban_reason = $1, banned_until = $2 WHERE id = $3`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		res, err := tx.ExecContext(ctx, query, reason, until, userID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+
+		// Sus access tokens los rechaza AuthTokenMiddleware al ver la suspensión.
+		if _, err := revokeUserSessions(ctx, tx, userID); err != nil {
+			return err
+		}
+		if err := revokeUserRefreshTokens(ctx, tx, userID); err != nil {
+			return err
+		}
+		return deleteUserAccessTokens(ctx, tx, userID)
+	})
+}
+
+// Unban levanta la suspensión del usuario.
+func (s *UserStore) Unban(ctx context.Context, userID int64) error {
+	query := `UPDATE users SET banned_at = NULL, ban_reason = '', banned_until = NULL WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
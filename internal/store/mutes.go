@@ -0,0 +1,67 @@
+// internal/store/mutes.go
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// MuteStore gestiona los silenciados: a diferencia de un bloqueo, silenciar
+// solo oculta las publicaciones del otro usuario en el feed de quien silencia.
+type MuteStore struct {
+	db *sql.DB
+}
+
+// Mute hace que muterID deje de ver en su feed las publicaciones de mutedID.
+func (s *MuteStore) Mute(ctx context.Context, muterID, mutedID int64) error {
+	if muterID == mutedID {
+		return ErrSelfBlock
+	}
+
+	query := `INSERT INTO user_mutes (muter_id, muted_id) VALUES ($1, $2)`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(ctx, query, muterID, mutedID); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code {
+			case "23505":
+				return ErrConflict
+			case "23503":
+				return ErrNotFound
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// Unmute deshace un silenciado. Devuelve ErrNotFound si no existía.
+func (s *MuteStore) Unmute(ctx context.Context, muterID, mutedID int64) error {
+	query := `DELETE FROM user_mutes WHERE muter_id = $1 AND muted_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, muterID, mutedID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetMuted lista los usuarios silenciados por userID.
+func (s *MuteStore) GetMuted(ctx context.Context, userID int64, q PaginatedQuery) ([]UserSummary, string, error) {
+	return listUserSummaries(ctx, s.db, "user_mutes", "muter_id", "muted_id", userID, q)
+}
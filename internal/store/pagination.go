@@ -0,0 +1,115 @@
+// internal/store/pagination.go
+package store
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// Cursor identifica la posición de un elemento en una lista ordenada por
+// (created_at, id). Es la base de nuestra paginación por "keyset".
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// Encode convierte el cursor en un string opaco para el cliente.
+func (c Cursor) Encode() string {
+	raw := fmt.Sprintf("%s|%d", c.CreatedAt.UTC().Format(time.RFC3339Nano), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor hace el camino inverso de Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if c.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.ID, err = strconv.ParseInt(id, 10, 64); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// PaginatedQuery agrupa los parámetros comunes a cualquier listado paginado.
+type PaginatedQuery struct {
+	Limit  int    `validate:"gte=1,lte=100"`
+	Sort   string `validate:"oneof=asc desc"`
+	Cursor *Cursor
+	Since  *time.Time
+	Until  *time.Time
+	Search string `validate:"max=100"`
+}
+
+// cursorOperator devuelve el comparador que hay que usar contra el cursor
+// según la dirección de ordenamiento.
+func (q PaginatedQuery) cursorOperator() string {
+	if q.Sort == "asc" {
+		return ">"
+	}
+	return "<"
+}
+
+// direction devuelve la dirección de ORDER BY, nunca un valor arbitrario del usuario.
+func (q PaginatedQuery) direction() string {
+	if q.Sort == "asc" {
+		return "ASC"
+	}
+	return "DESC"
+}
+
+// cursorArgs devuelve los valores del cursor listos para la consulta (NULL si no hay).
+func (q PaginatedQuery) cursorArgs() (any, any) {
+	if q.Cursor == nil {
+		return nil, nil
+	}
+	return q.Cursor.CreatedAt, q.Cursor.ID
+}
+
+// timeArg convierte un filtro de fecha opcional en un argumento SQL.
+func timeArg(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+// paginate recibe hasta limit+1 elementos: si sobra uno, hay otra página y
+// devolvemos el cursor del último elemento visible.
+func paginate[T any](items []T, limit int, cursorOf func(T) (Cursor, error)) ([]T, string, error) {
+	if len(items) <= limit {
+		return items, "", nil
+	}
+
+	items = items[:limit]
+	c, err := cursorOf(items[len(items)-1])
+	if err != nil {
+		return nil, "", err
+	}
+	return items, c.Encode(), nil
+}
+
+// newCursor construye un cursor a partir del created_at tal y como lo escaneamos.
+func newCursor(createdAt string, id int64) (Cursor, error) {
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return Cursor{}, err
+	}
+	return Cursor{CreatedAt: t, ID: id}, nil
+}
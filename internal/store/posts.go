@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 )
 
 type Post struct {
@@ -16,6 +17,8 @@ type Post struct {
 	Version   int       `json:"version"`
 	User      User      `json:"user"`     // ¡Campo nuevo!
 	Comments  []Comment `json:"comments"` // <-- AÑADE ESTO
+	// Reactions solo se rellena al leer la publicación (detalle y feed).
+	Reactions *ReactionSummary `json:"reactions,omitempty"`
 }
 
 type PostStore struct {
@@ -100,32 +103,54 @@ type PostWithMetadata struct {
 	User          User `json:"user,omitempty"`
 }
 
-// GetUserFeed recupera las publicaciones de los usuarios que sigue el userID.
-func (s *PostStore) GetUserFeed(ctx context.Context, userID int64) ([]PostWithMetadata, error) {
-	query := `
+// GetUserFeed recupera las publicaciones propias del userID y las de los usuarios que sigue.
+// Devuelve además el cursor de la siguiente página (vacío si no hay más).
+func (s *PostStore) GetUserFeed(ctx context.Context, userID int64, q PaginatedQuery) ([]PostWithMetadata, string, error) {
+	// Usamos EXISTS en lugar de un JOIN con followers: un JOIN descartaría las
+	// publicaciones propias cuando el usuario no sigue a nadie y duplicaría filas.
+	// Los contadores son subconsultas por fila, así no necesitamos GROUP BY ni N+1.
+	// Se excluyen los autores bloqueados (en cualquier dirección) y los silenciados.
+	// Las cuentas privadas no necesitan otra condición: solo se sigue a una tras
+	// aprobar la solicitud, así que sus posts solo llegan a seguidores aprobados.
+	query := fmt.Sprintf(`
 		SELECT
 			p.id, p.user_id, p.title, p.content, p.created_at, p.version,
-			u.username
+			u.username, r.id, r.name, r.level,
+			(SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id) AS comments_count,
+			%s
 		FROM posts p
 		JOIN users u ON p.user_id = u.id
-		JOIN followers f ON f.user_id = p.user_id
-		WHERE f.follower_id = $1 OR p.user_id = $1
-		GROUP BY p.id, u.username
-		ORDER BY p.created_at DESC
-		LIMIT 20`
+		JOIN roles r ON u.role_id = r.id
+		WHERE u.deleted_at IS NULL
+			AND (
+				p.user_id = $1
+				OR EXISTS (SELECT 1 FROM followers f WHERE f.user_id = p.user_id AND f.follower_id = $1)
+			)
+			AND NOT %s
+			AND NOT EXISTS (SELECT 1 FROM user_mutes m WHERE m.muter_id = $1 AND m.muted_id = p.user_id)
+			AND ($2::timestamptz IS NULL OR (p.created_at, p.id) %s ($2::timestamptz, $3::bigint))
+			AND ($4::timestamptz IS NULL OR p.created_at >= $4)
+			AND ($5::timestamptz IS NULL OR p.created_at <= $5)
+			AND ($6 = '' OR to_tsvector('simple', p.title || ' ' || p.content) @@ plainto_tsquery('simple', $6))
+		ORDER BY p.created_at %s, p.id %s
+		LIMIT $7`, reactionColumns("post_reactions", "post_id", "p.id", "$1"), blockedBetween("$1", "p.user_id"), q.cursorOperator(), q.direction(), q.direction())
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := s.db.QueryContext(ctx, query, userID)
+	cursorTime, cursorID := q.cursorArgs()
+	// Pedimos un elemento de más para saber si existe otra página.
+	rows, err := s.db.QueryContext(ctx, query, userID, cursorTime, cursorID, timeArg(q.Since), timeArg(q.Until), q.Search, q.Limit+1)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
 
 	var feed []PostWithMetadata
 	for rows.Next() {
 		var p PostWithMetadata
+		var reactionCounts []byte
+		var myReaction sql.NullString
 		err := rows.Scan(
 			&p.ID,
 			&p.UserID,
@@ -134,15 +159,31 @@ func (s *PostStore) GetUserFeed(ctx context.Context, userID int64) ([]PostWithMe
 			&p.CreatedAt,
 			&p.Version,
 			&p.User.Username, // <-- Ahora SÍ existe p.User.Username
+			&p.User.Role.ID,
+			&p.User.Role.Name,
+			&p.User.Role.Level,
+			&p.CommentsCount,
+			&reactionCounts,
+			&myReaction,
 		)
 		if err != nil {
-			return nil, err
+			return nil, "", err
+		}
+
+		p.Reactions, err = newReactionSummary(reactionCounts, myReaction)
+		if err != nil {
+			return nil, "", err
 		}
 
 		// Asignamos el ID del usuario de la publicación
 		p.User.ID = p.UserID
 		feed = append(feed, p)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
 
-	return feed, nil
+	return paginate(feed, q.Limit, func(p PostWithMetadata) (Cursor, error) {
+		return newCursor(p.CreatedAt, p.ID)
+	})
 }
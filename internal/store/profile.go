@@ -0,0 +1,160 @@
+// internal/store/profile.go
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// UpdateProfile guarda los datos públicos del perfil del usuario. Si la cuenta
+// es pública, las solicitudes de seguimiento pendientes quedan aprobadas.
+func (s *UserStore) UpdateProfile(ctx context.Context, user *User) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE users SET username = $1, display_name = $2, bio = $3, avatar_url = $4, is_private = $5
+			WHERE id = $6 AND deleted_at IS NULL`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		res, err := tx.ExecContext(ctx, query, user.Username, user.DisplayName, user.Bio, user.AvatarURL, user.IsPrivate, user.ID)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == "users_username_key" {
+				return ErrDuplicateUsername
+			}
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+
+		if user.IsPrivate {
+			return nil
+		}
+		return approveFollowRequests(ctx, tx, user.ID)
+	})
+}
+
+// ChangePassword guarda la nueva contraseña del usuario y cierra todas sus
+// sesiones salvo keepSessionID (la que hizo el cambio; "" para cerrarlas todas).
+// Devuelve los ids de las sesiones cerradas.
+func (s *UserStore) ChangePassword(ctx context.Context, user *User, newPassword, keepSessionID string) ([]string, error) {
+	if err := user.Password.Set(newPassword); err != nil {
+		return nil, err
+	}
+
+	var revoked []string
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.updatePassword(ctx, tx, user); err != nil {
+			return err
+		}
+		if err := s.deletePasswordResets(ctx, tx, user.ID); err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		query := `
+			UPDATE sessions SET revoked_at = NOW()
+			WHERE user_id = $1 AND revoked_at IS NULL AND id::text <> $2
+			RETURNING id`
+
+		rows, err := tx.QueryContext(ctx, query, user.ID, keepSessionID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			revoked = append(revoked, id)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		query = `
+			UPDATE refresh_tokens SET revoked_at = NOW()
+			WHERE user_id = $1 AND revoked_at IS NULL AND family_id::text <> $2`
+
+		_, err = tx.ExecContext(ctx, query, user.ID, keepSessionID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revoked, nil
+}
+
+// SoftDelete marca la cuenta como borrada y cierra todas sus sesiones. La
+// cuenta se puede recuperar iniciando sesión antes de que se purgue.
+// Devuelve los ids de las sesiones cerradas.
+func (s *UserStore) SoftDelete(ctx context.Context, userID int64) ([]string, error) {
+	var revoked []string
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `UPDATE users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		res, err := tx.ExecContext(ctx, query, userID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+
+		if revoked, err = revokeUserSessions(ctx, tx, userID); err != nil {
+			return err
+		}
+		return revokeUserRefreshTokens(ctx, tx, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revoked, nil
+}
+
+// Restore cancela el borrado de una cuenta que todavía no se purgó.
+func (s *UserStore) Restore(ctx context.Context, userID int64) error {
+	query := `UPDATE users SET deleted_at = NULL WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, userID)
+	return err
+}
+
+// PurgeDeleted borra definitivamente las cuentas borradas hace más de grace.
+// Sus posts, comentarios y seguidores desaparecen con ON DELETE CASCADE.
+func (s *UserStore) PurgeDeleted(ctx context.Context, grace time.Duration) (int64, error) {
+	query := `DELETE FROM users WHERE deleted_at < $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, time.Now().Add(-grace))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,172 @@
+// internal/store/reactions.go
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// ErrUnknownReaction indica que el tipo de reacción no está entre ReactionKinds.
+var ErrUnknownReaction = errors.New("unknown reaction kind")
+
+// ReactionKinds son los tipos de reacción permitidos. Deben coincidir con el
+// CHECK de las tablas post_reactions y comment_reactions.
+var ReactionKinds = []string{"like", "love", "haha", "wow", "sad", "angry"}
+
+// IsReactionKind indica si kind es un tipo de reacción permitido.
+func IsReactionKind(kind string) bool {
+	for _, k := range ReactionKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ReactionSummary resume las reacciones de una publicación o comentario:
+// cuántas hay de cada tipo y cuál dejó quien consulta (nil si ninguna).
+type ReactionSummary struct {
+	Counts     map[string]int `json:"counts"`
+	MyReaction *string        `json:"my_reaction"`
+}
+
+// reactionColumns devuelve las dos columnas SQL que forman un ReactionSummary:
+// los contadores como objeto JSON y la reacción de viewer.
+// table es la tabla de reacciones, column la columna que apunta al elemento
+// (post_id, comment_id) y target/viewer expresiones SQL (columnas o parámetros).
+func reactionColumns(table, column, target, viewer string) string {
+	return fmt.Sprintf(`
+		COALESCE((
+			SELECT json_object_agg(t.kind, t.total) FROM (
+				SELECT rc.kind, COUNT(*) AS total FROM %[1]s rc
+				WHERE rc.%[2]s = %[3]s
+				GROUP BY rc.kind
+			) t
+		), '{}'),
+		(SELECT rm.kind FROM %[1]s rm WHERE rm.%[2]s = %[3]s AND rm.user_id = %[4]s)`,
+		table, column, target, viewer)
+}
+
+// newReactionSummary construye el resumen a partir de las columnas de reactionColumns.
+func newReactionSummary(counts []byte, mine sql.NullString) (*ReactionSummary, error) {
+	summary := &ReactionSummary{Counts: map[string]int{}}
+	if err := json.Unmarshal(counts, &summary.Counts); err != nil {
+		return nil, err
+	}
+	if mine.Valid {
+		summary.MyReaction = &mine.String
+	}
+	return summary, nil
+}
+
+type ReactionStore struct {
+	db *sql.DB
+}
+
+// ReactToPost deja (o cambia) la reacción de userID en la publicación.
+// Repetir la misma reacción no tiene efecto.
+func (s *ReactionStore) ReactToPost(ctx context.Context, postID, userID int64, kind string) error {
+	query := `
+		INSERT INTO post_reactions (post_id, user_id, kind)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (post_id, user_id) DO UPDATE SET kind = EXCLUDED.kind`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, postID, userID, kind)
+	return reactionError(err)
+}
+
+// UnreactPost quita la reacción kind de userID en la publicación.
+// Devuelve ErrNotFound si no había reaccionado con ese tipo.
+func (s *ReactionStore) UnreactPost(ctx context.Context, postID, userID int64, kind string) error {
+	query := `DELETE FROM post_reactions WHERE post_id = $1 AND user_id = $2 AND kind = $3`
+	return s.unreact(ctx, query, postID, userID, kind)
+}
+
+// ReactToComment deja (o cambia) la reacción de userID en un comentario de la
+// publicación postID. Devuelve ErrNotFound si el comentario no pertenece a la
+// publicación o si hay un bloqueo entre userID y su autor.
+func (s *ReactionStore) ReactToComment(ctx context.Context, postID, commentID, userID int64, kind string) error {
+	query := `
+		INSERT INTO comment_reactions (comment_id, user_id, kind)
+		SELECT c.id, $3, $4 FROM comments c
+		WHERE c.id = $2 AND c.post_id = $1 AND NOT ` + blockedBetween("$3", "c.user_id") + `
+		ON CONFLICT (comment_id, user_id) DO UPDATE SET kind = EXCLUDED.kind
+		RETURNING true`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var ok bool
+	err := s.db.QueryRowContext(ctx, query, postID, commentID, userID, kind).Scan(&ok)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return reactionError(err)
+}
+
+// UnreactComment quita la reacción kind de userID en un comentario de la publicación.
+// Devuelve ErrNotFound si no había reaccionado con ese tipo.
+func (s *ReactionStore) UnreactComment(ctx context.Context, postID, commentID, userID int64, kind string) error {
+	query := `
+		DELETE FROM comment_reactions cr
+		USING comments c
+		WHERE c.id = cr.comment_id AND c.post_id = $1
+			AND cr.comment_id = $2 AND cr.user_id = $3 AND cr.kind = $4`
+	return s.unreact(ctx, query, postID, commentID, userID, kind)
+}
+
+// GetPostSummary devuelve el resumen de reacciones de la publicación visto por viewerID.
+func (s *ReactionStore) GetPostSummary(ctx context.Context, postID, viewerID int64) (*ReactionSummary, error) {
+	query := `SELECT ` + reactionColumns("post_reactions", "post_id", "$1", "$2")
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var counts []byte
+	var mine sql.NullString
+	if err := s.db.QueryRowContext(ctx, query, postID, viewerID).Scan(&counts, &mine); err != nil {
+		return nil, err
+	}
+	return newReactionSummary(counts, mine)
+}
+
+// unreact ejecuta un DELETE de reacción y devuelve ErrNotFound si no borró nada.
+func (s *ReactionStore) unreact(ctx context.Context, query string, args ...any) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// reactionError traduce los errores de Postgres al insertar una reacción.
+func reactionError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23514": // *_reactions_kind_check
+			return ErrUnknownReaction
+		case "23503": // el post o el usuario ya no existen
+			return ErrNotFound
+		}
+	}
+	return err
+}
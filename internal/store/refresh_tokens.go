@@ -0,0 +1,156 @@
+// internal/store/refresh_tokens.go
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrTokenReused indica que alguien presentó un refresh token que ya había sido rotado.
+var ErrTokenReused = errors.New("refresh token has already been used")
+
+// RefreshToken define el modelo de un refresh token persistido (solo su hash).
+type RefreshToken struct {
+	ID        int64
+	UserID    int64
+	FamilyID  string
+	Expiry    time.Time
+	RevokedAt *time.Time
+}
+
+type RefreshTokenStore struct {
+	db *sql.DB
+}
+
+// Rotate consume el refresh token actual y guarda uno nuevo en la misma familia.
+// Si el token ya había sido usado, asumimos que fue robado y revocamos la familia
+// entera junto con su sesión; en ese caso devuelve el token y ErrTokenReused.
+func (s *RefreshTokenStore) Rotate(ctx context.Context, oldHash, newHash []byte, exp time.Duration) (*RefreshToken, error) {
+	var (
+		token  *RefreshToken
+		reused bool
+	)
+
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		current, err := s.getForUpdate(ctx, tx, oldHash)
+		if err != nil {
+			return err
+		}
+
+		if current.RevokedAt != nil {
+			// Devolvemos nil para que la transacción confirme la revocación.
+			reused = true
+			token = current
+			if err := revokeSession(ctx, tx, current.FamilyID); err != nil {
+				return err
+			}
+			return revokeRefreshTokenFamily(ctx, tx, current.FamilyID)
+		}
+
+		if current.Expiry.Before(time.Now()) {
+			return ErrNotFound
+		}
+
+		if err := s.revoke(ctx, tx, current.ID); err != nil {
+			return err
+		}
+		if err := createRefreshToken(ctx, tx, current.UserID, current.FamilyID, newHash, exp); err != nil {
+			return err
+		}
+
+		token = current
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return token, ErrTokenReused
+	}
+	return token, nil
+}
+
+// RevokeFamilyByToken revoca la familia a la que pertenece el token y cierra
+// su sesión (logout). Solo afecta a tokens del propio usuario.
+func (s *RefreshTokenStore) RevokeFamilyByToken(ctx context.Context, userID int64, tokenHash []byte) error {
+	query := `
+		WITH family AS (
+			SELECT family_id FROM refresh_tokens WHERE token = $1 AND user_id = $2
+		), revoked AS (
+			UPDATE refresh_tokens SET revoked_at = NOW()
+			WHERE revoked_at IS NULL AND family_id IN (SELECT family_id FROM family)
+		)
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE revoked_at IS NULL AND id IN (SELECT family_id FROM family)`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, tokenHash, userID)
+	return err
+}
+
+// RevokeAllForUser revoca todos los refresh tokens de un usuario.
+func (s *RefreshTokenStore) RevokeAllForUser(ctx context.Context, userID int64) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return revokeUserRefreshTokens(ctx, tx, userID)
+	})
+}
+
+// createRefreshToken guarda un refresh token. La familia es el id de la sesión.
+func createRefreshToken(ctx context.Context, tx *sql.Tx, userID int64, familyID string, tokenHash []byte, exp time.Duration) error {
+	query := `INSERT INTO refresh_tokens (token, user_id, family_id, expiry) VALUES ($1, $2, $3, $4)`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	_, err := tx.ExecContext(ctx, query, tokenHash, userID, familyID, time.Now().Add(exp))
+	return err
+}
+
+func (s *RefreshTokenStore) getForUpdate(ctx context.Context, tx *sql.Tx, tokenHash []byte) (*RefreshToken, error) {
+	query := `
+		SELECT id, user_id, family_id, expiry, revoked_at
+		FROM refresh_tokens
+		WHERE token = $1
+		FOR UPDATE`
+
+	var token RefreshToken
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := tx.QueryRowContext(ctx, query, tokenHash).Scan(&token.ID, &token.UserID, &token.FamilyID, &token.Expiry, &token.RevokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *RefreshTokenStore) revoke(ctx context.Context, tx *sql.Tx, id int64) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = $1`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	_, err := tx.ExecContext(ctx, query, id)
+	return err
+}
+
+func revokeRefreshTokenFamily(ctx context.Context, tx *sql.Tx, familyID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	_, err := tx.ExecContext(ctx, query, familyID)
+	return err
+}
+
+// revokeUserRefreshTokens es una función suelta para poder usarla desde
+// transacciones de otros stores (ej. al restablecer la contraseña).
+func revokeUserRefreshTokens(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	_, err := tx.ExecContext(ctx, query, userID)
+	return err
+}
@@ -4,24 +4,271 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
 )
 
+// ErrUnknownPermission indica que se intentó asignar un permiso que no existe.
+var ErrUnknownPermission = errors.New("unknown permission")
+
+// Permission es una acción con nombre que se puede conceder a un rol.
+type Permission struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
 type RoleStore struct {
 	db *sql.DB
 }
 
 // GetByName busca un rol por su nombre (ej. "admin").
 func (s *RoleStore) GetByName(ctx context.Context, name string) (*Role, error) {
-	query := `SELECT id, name, level FROM roles WHERE name = $1`
+	query := `
+		SELECT r.id, r.name, r.level, r.require_2fa,
+		       ARRAY(
+		           SELECT p.name FROM role_permissions rp
+		           JOIN permissions p ON p.id = rp.permission_id
+		           WHERE rp.role_id = r.id
+		       )
+		FROM roles r
+		WHERE r.name = $1`
 
 	var role Role
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	err := s.db.QueryRowContext(ctx, query, name).Scan(&role.ID, &role.Name, &role.Level)
+	err := s.db.QueryRowContext(ctx, query, name).Scan(
+		&role.ID, &role.Name, &role.Level, &role.Require2FA, pq.Array(&role.Permissions),
+	)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
 		return nil, err
 	}
 
 	return &role, nil
 }
+
+// GetByID busca un rol por su id junto con sus permisos.
+func (s *RoleStore) GetByID(ctx context.Context, id int64) (*Role, error) {
+	query := `
+		SELECT r.id, r.name, r.level, r.require_2fa,
+		       ARRAY(
+		           SELECT p.name FROM role_permissions rp
+		           JOIN permissions p ON p.id = rp.permission_id
+		           WHERE rp.role_id = r.id
+		       )
+		FROM roles r
+		WHERE r.id = $1`
+
+	var role Role
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&role.ID, &role.Name, &role.Level, &role.Require2FA, pq.Array(&role.Permissions),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// GetAll lista todos los roles con sus permisos.
+func (s *RoleStore) GetAll(ctx context.Context) ([]Role, error) {
+	query := `
+		SELECT r.id, r.name, r.level, r.require_2fa,
+		       ARRAY(
+		           SELECT p.name FROM role_permissions rp
+		           JOIN permissions p ON p.id = rp.permission_id
+		           WHERE rp.role_id = r.id
+		       )
+		FROM roles r
+		ORDER BY r.level, r.id`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []Role{}
+	for rows.Next() {
+		var role Role
+		if err := rows.Scan(&role.ID, &role.Name, &role.Level, &role.Require2FA, pq.Array(&role.Permissions)); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+// Create crea un rol sin permisos.
+func (s *RoleStore) Create(ctx context.Context, role *Role) error {
+	query := `INSERT INTO roles (name, level, require_2fa) VALUES ($1, $2, $3) RETURNING id`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query, role.Name, role.Level, role.Require2FA).Scan(&role.ID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrConflict
+		}
+		return err
+	}
+
+	role.Permissions = []string{}
+	return nil
+}
+
+// Update cambia el nombre, el nivel y la exigencia de 2FA de un rol.
+func (s *RoleStore) Update(ctx context.Context, role *Role) error {
+	query := `UPDATE roles SET name = $1, level = $2, require_2fa = $3 WHERE id = $4`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, role.Name, role.Level, role.Require2FA, role.ID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrConflict
+		}
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete borra un rol. Devuelve ErrConflict si todavía hay usuarios con ese rol.
+func (s *RoleStore) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM roles WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		// 23503: violación de clave foránea (users.role_id)
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return ErrConflict
+		}
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetUserIDs lista los ids de los usuarios que tienen el rol.
+func (s *RoleStore) GetUserIDs(ctx context.Context, roleID int64) ([]int64, error) {
+	query := `SELECT id FROM users WHERE role_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// GetPermissions lista todos los permisos que existen.
+func (s *RoleStore) GetPermissions(ctx context.Context) ([]Permission, error) {
+	query := `SELECT id, name, description FROM permissions ORDER BY name`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []Permission{}
+	for rows.Next() {
+		var p Permission
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, p)
+	}
+
+	return permissions, rows.Err()
+}
+
+// SetPermissions reemplaza los permisos del rol por los indicados.
+func (s *RoleStore) SetPermissions(ctx context.Context, roleID int64, permissions []string) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM roles WHERE id = $1)`, roleID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrNotFound
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, roleID); err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO role_permissions (role_id, permission_id)
+			SELECT $1, id FROM permissions WHERE name = ANY($2)`
+
+		res, err := tx.ExecContext(ctx, query, roleID, pq.Array(permissions))
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		// Si falta alguna fila es que algún nombre no existe.
+		if rows != int64(len(permissions)) {
+			return ErrUnknownPermission
+		}
+		return nil
+	})
+}
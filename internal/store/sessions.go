@@ -0,0 +1,165 @@
+// internal/store/sessions.go
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Session representa un login de un usuario en un dispositivo.
+type Session struct {
+	ID         string `json:"id"`
+	UserID     int64  `json:"-"`
+	Device     string `json:"device"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	CreatedAt  string `json:"created_at"`
+	LastSeenAt string `json:"last_seen_at"`
+	Current    bool   `json:"current"`
+}
+
+type SessionStore struct {
+	db *sql.DB
+}
+
+// Create guarda la sesión y su primer refresh token en la misma transacción.
+func (s *SessionStore) Create(ctx context.Context, session *Session, refreshHash []byte, exp time.Duration) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO sessions (id, user_id, device, user_agent, ip)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING created_at, last_seen_at`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		err := tx.QueryRowContext(ctx, query, session.ID, session.UserID, session.Device, session.UserAgent, session.IP).Scan(
+			&session.CreatedAt, &session.LastSeenAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		return createRefreshToken(ctx, tx, session.UserID, session.ID, refreshHash, exp)
+	})
+}
+
+// GetActiveByUserID lista las sesiones abiertas del usuario. Una sesión sin
+// actividad durante más de idle ya no tiene refresh tokens válidos.
+func (s *SessionStore) GetActiveByUserID(ctx context.Context, userID int64, idle time.Duration) ([]Session, error) {
+	query := `
+		SELECT id, user_id, device, user_agent, ip, created_at, last_seen_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND last_seen_at > $2
+		ORDER BY last_seen_at DESC`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID, time.Now().Add(-idle))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var session Session
+		err := rows.Scan(&session.ID, &session.UserID, &session.Device, &session.UserAgent, &session.IP, &session.CreatedAt, &session.LastSeenAt)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Touch registra actividad en la sesión (se llama al refrescar los tokens).
+func (s *SessionStore) Touch(ctx context.Context, id, ip, userAgent string) error {
+	query := `UPDATE sessions SET last_seen_at = NOW(), ip = $2, user_agent = $3 WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, id, ip, userAgent)
+	return err
+}
+
+// Revoke cierra una sesión del usuario y revoca sus refresh tokens.
+func (s *SessionStore) Revoke(ctx context.Context, userID int64, id string) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		res, err := tx.ExecContext(ctx, query, id, userID)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+
+		return revokeRefreshTokenFamily(ctx, tx, id)
+	})
+}
+
+// RevokeAll cierra todas las sesiones del usuario ("cerrar sesión en todas partes")
+// y borra sus tokens personales. Devuelve los ids de las sesiones, para revocar
+// también sus access tokens.
+func (s *SessionStore) RevokeAll(ctx context.Context, userID int64) ([]string, error) {
+	var revoked []string
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var err error
+		if revoked, err = revokeUserSessions(ctx, tx, userID); err != nil {
+			return err
+		}
+		if err := revokeUserRefreshTokens(ctx, tx, userID); err != nil {
+			return err
+		}
+		return deleteUserAccessTokens(ctx, tx, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revoked, nil
+}
+
+func revokeSession(ctx context.Context, tx *sql.Tx, id string) error {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	_, err := tx.ExecContext(ctx, query, id)
+	return err
+}
+
+// revokeUserSessions es una función suelta para usarla desde otros stores.
+// Devuelve los ids de las sesiones que cerró.
+func revokeUserSessions(ctx context.Context, tx *sql.Tx, userID int64) ([]string, error) {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL RETURNING id`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revoked := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		revoked = append(revoked, id)
+	}
+	return revoked, rows.Err()
+}
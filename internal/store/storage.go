@@ -14,19 +14,37 @@ var (
 )
 
 type Storage struct {
-	Users     *UserStore
-	Posts     *PostStore
-	Followers *FollowerStore
-	Roles     *RoleStore
-	Comments  *CommentStore // <-- AÑADE ESTO
+	Users         *UserStore
+	Posts         *PostStore
+	Followers     *FollowerStore
+	Roles         *RoleStore
+	Comments      *CommentStore // <-- AÑADE ESTO
+	RefreshTokens *RefreshTokenStore
+	LoginAttempts *LoginAttemptStore
+	Identities    *IdentityStore
+	AccessTokens  *AccessTokenStore
+	Sessions      *SessionStore
+	AuditLogs     *AuditLogStore
+	Blocks        *BlockStore
+	Mutes         *MuteStore
+	Reactions     *ReactionStore
 }
 
 func NewStorage(db *sql.DB) Storage {
 	return Storage{
-		Users:     &UserStore{db: db},
-		Posts:     &PostStore{db: db},
-		Followers: &FollowerStore{db: db},
-		Roles:     &RoleStore{db: db},
-		Comments:  &CommentStore{db: db}, // <-- Y ESTO
+		Users:         &UserStore{db: db},
+		Posts:         &PostStore{db: db},
+		Followers:     &FollowerStore{db: db},
+		Roles:         &RoleStore{db: db},
+		Comments:      &CommentStore{db: db}, // <-- Y ESTO
+		RefreshTokens: &RefreshTokenStore{db: db},
+		LoginAttempts: &LoginAttemptStore{db: db},
+		Identities:    &IdentityStore{db: db},
+		AccessTokens:  &AccessTokenStore{db: db},
+		Sessions:      &SessionStore{db: db},
+		AuditLogs:     &AuditLogStore{db: db},
+		Blocks:        &BlockStore{db: db},
+		Mutes:         &MuteStore{db: db},
+		Reactions:     &ReactionStore{db: db},
 	}
 }
@@ -0,0 +1,91 @@
+// internal/store/suggestions.go
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+// Suggestion es un usuario que quizá conozcas, con los motivos de la sugerencia.
+type Suggestion struct {
+	ID             int64  `json:"id"`
+	Username       string `json:"username"`
+	DisplayName    string `json:"display_name"`
+	AvatarURL      string `json:"avatar_url"`
+	MutualFollows  int    `json:"mutual_follows"`  // Cuántos de tus seguidos lo siguen
+	SharedComments int    `json:"shared_comments"` // Posts en los que ambos comentasteis
+	RecentlyActive bool   `json:"recently_active"` // Publicó en los últimos 7 días
+}
+
+// GetSuggestions calcula hasta limit usuarios que userID podría querer seguir.
+// Los candidatos salen de los seguidos de sus seguidos, de quienes comentan en
+// los mismos posts y de los usuarios activos últimamente (para cuentas nuevas).
+// Se excluyen los que ya sigue o a los que ya pidió seguir, los bloqueados y
+// los silenciados.
+func (s *FollowerStore) GetSuggestions(ctx context.Context, userID int64, limit int) ([]Suggestion, error) {
+	query := fmt.Sprintf(`
+		WITH friends_of_friends AS (
+			SELECT f2.user_id AS candidate_id, COUNT(*) AS mutual
+			FROM followers f1
+			JOIN followers f2 ON f2.follower_id = f1.user_id
+			WHERE f1.follower_id = $1
+			GROUP BY f2.user_id
+		), commenters AS (
+			SELECT c2.user_id AS candidate_id, COUNT(DISTINCT c2.post_id) AS shared
+			FROM comments c1
+			JOIN comments c2 ON c2.post_id = c1.post_id AND c2.user_id <> c1.user_id
+			WHERE c1.user_id = $1
+			GROUP BY c2.user_id
+		), active AS (
+			SELECT user_id AS candidate_id
+			FROM posts
+			WHERE created_at > NOW() - INTERVAL '7 days'
+			GROUP BY user_id
+			ORDER BY MAX(created_at) DESC
+			LIMIT 100
+		), candidates AS (
+			SELECT candidate_id FROM friends_of_friends
+			UNION SELECT candidate_id FROM commenters
+			UNION SELECT candidate_id FROM active
+		)
+		SELECT
+			u.id, u.username, u.display_name, u.avatar_url,
+			COALESCE(fof.mutual, 0), COALESCE(cm.shared, 0), a.candidate_id IS NOT NULL
+		FROM candidates c
+		JOIN users u ON u.id = c.candidate_id
+		LEFT JOIN friends_of_friends fof ON fof.candidate_id = u.id
+		LEFT JOIN commenters cm ON cm.candidate_id = u.id
+		LEFT JOIN active a ON a.candidate_id = u.id
+		WHERE u.id <> $1
+			AND %s
+			AND NOT EXISTS (SELECT 1 FROM followers f WHERE f.user_id = u.id AND f.follower_id = $1)
+			AND NOT EXISTS (SELECT 1 FROM follow_requests fr WHERE fr.user_id = u.id AND fr.requester_id = $1)
+			AND NOT EXISTS (SELECT 1 FROM user_mutes m WHERE m.muter_id = $1 AND m.muted_id = u.id)
+			AND NOT %s
+		ORDER BY
+			COALESCE(fof.mutual, 0) * 3 + COALESCE(cm.shared, 0) * 2
+				+ CASE WHEN a.candidate_id IS NULL THEN 0 ELSE 1 END DESC,
+			u.id
+		LIMIT $2`, visibleUser, blockedBetween("$1", "u.id"))
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []Suggestion{}
+	for rows.Next() {
+		var sg Suggestion
+		err := rows.Scan(&sg.ID, &sg.Username, &sg.DisplayName, &sg.AvatarURL, &sg.MutualFollows, &sg.SharedComments, &sg.RecentlyActive)
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, sg)
+	}
+
+	return suggestions, rows.Err()
+}
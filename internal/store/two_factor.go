@@ -0,0 +1,123 @@
+// internal/store/two_factor.go
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// SetTOTPSecret guarda un secreto pendiente de verificar. Mientras no se llame
+// a EnableTwoFactor, el login sigue sin pedir el segundo factor.
+func (s *UserStore) SetTOTPSecret(ctx context.Context, userID int64, secret string) error {
+	query := `UPDATE users SET totp_secret = $1, totp_last_counter = NULL WHERE id = $2 AND totp_enabled = FALSE`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, secret, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrConflict // Ya tenía 2FA activado
+	}
+	return nil
+}
+
+// EnableTwoFactor activa 2FA y sustituye los códigos de recuperación por los nuevos (ya hasheados).
+func (s *UserStore) EnableTwoFactor(ctx context.Context, userID int64, recoveryCodes [][]byte) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `UPDATE users SET totp_enabled = TRUE WHERE id = $1 AND totp_secret IS NOT NULL`
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+			return err
+		}
+		if err := s.deleteRecoveryCodes(ctx, tx, userID); err != nil {
+			return err
+		}
+		for _, code := range recoveryCodes {
+			query := `INSERT INTO user_recovery_codes (user_id, code) VALUES ($1, $2)`
+			if _, err := tx.ExecContext(ctx, query, userID, code); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DisableTwoFactor borra el secreto y los códigos de recuperación del usuario.
+func (s *UserStore) DisableTwoFactor(ctx context.Context, userID int64) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `UPDATE users SET totp_secret = NULL, totp_enabled = FALSE, totp_last_counter = NULL WHERE id = $1`
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+			return err
+		}
+		return s.deleteRecoveryCodes(ctx, tx, userID)
+	})
+}
+
+// UseTOTPCounter registra que se aceptó un código del periodo counter. Devuelve
+// ErrConflict si ya se había aceptado un código de ese periodo o de uno
+// posterior: el código es un reenvío. La comprobación es atómica, así que dos
+// peticiones simultáneas con el mismo código no pueden pasar las dos.
+func (s *UserStore) UseTOTPCounter(ctx context.Context, userID, counter int64) error {
+	query := `
+		UPDATE users SET totp_last_counter = $2
+		WHERE id = $1 AND (totp_last_counter IS NULL OR totp_last_counter < $2)`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, userID, counter)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// UseRecoveryCode consume un código de recuperación. Cada código sirve una sola vez.
+func (s *UserStore) UseRecoveryCode(ctx context.Context, userID int64, codeHash []byte) error {
+	query := `
+		UPDATE user_recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code = $2 AND used_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *UserStore) deleteRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `DELETE FROM user_recovery_codes WHERE user_id = $1`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	_, err := tx.ExecContext(ctx, query, userID)
+	return err
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -17,9 +18,21 @@ var (
 
 // Role define el modelo de datos para un rol.
 type Role struct {
-	ID    int64  `json:"id"`
-	Name  string `json:"name"`
-	Level int    `json:"level"`
+	ID          int64    `json:"id"`
+	Name        string   `json:"name"`
+	Level       int      `json:"level"`
+	Require2FA  bool     `json:"require_2fa"` // Los usuarios del rol deben activar 2FA
+	Permissions []string `json:"permissions"`
+}
+
+// HasPermission indica si el rol tiene el permiso (ej. "post.delete.any").
+func (r Role) HasPermission(permission string) bool {
+	for _, p := range r.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
 }
 
 // User define nuestro modelo de datos.
@@ -32,6 +45,27 @@ type User struct {
 	IsActive  bool     `json:"is_active"`
 	RoleID    int64    `json:"-"`    // No lo exponemos en el JSON
 	Role      Role     `json:"role"` // Struct anidada con la info del rol
+
+	DisplayName string     `json:"display_name"`
+	Bio         string     `json:"bio"`
+	AvatarURL   string     `json:"avatar_url"`
+	IsPrivate   bool       `json:"is_private"`           // Solo los seguidores aprobados ven sus posts
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"` // Borrado pendiente de purgar
+
+	TwoFactorEnabled bool   `json:"two_factor_enabled"`
+	TOTPSecret       string `json:"-"` // Nunca sale en el JSON (ni en la caché)
+
+	BannedAt    *time.Time `json:"banned_at,omitempty"`
+	BanReason   string     `json:"ban_reason,omitempty"`
+	BannedUntil *time.Time `json:"banned_until,omitempty"` // nil = indefinida
+}
+
+// IsBanned indica si el usuario tiene una suspensión en vigor.
+func (u *User) IsBanned() bool {
+	if u.BannedAt == nil {
+		return false
+	}
+	return u.BannedUntil == nil || u.BannedUntil.After(time.Now())
 }
 
 type password struct {
@@ -81,6 +115,51 @@ func (s *UserStore) CreateAndInvite(ctx context.Context, user *User, tokenHash [
 	})
 }
 
+// CreateWithIdentity crea un usuario ya activo a partir de una cuenta externa
+// (el proveedor verificó su email) y lo vincula a ella.
+func (s *UserStore) CreateWithIdentity(ctx context.Context, user *User, identity *Identity) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.Create(ctx, tx, user); err != nil {
+			return err
+		}
+		user.IsActive = true
+		if err := s.update(ctx, tx, user); err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return createIdentity(ctx, tx, identity)
+	})
+}
+
+// ActivateWithIdentity activa la cuenta pendiente de activación con ese email y
+// la vincula a una cuenta externa cuyo proveedor verificó el email. La
+// contraseña se sustituye por newPassword: la que se puso al registrarse no la
+// confirmó nadie con acceso al correo. Devuelve ErrNotFound si no hay ninguna
+// cuenta pendiente con ese email.
+func (s *UserStore) ActivateWithIdentity(ctx context.Context, email, newPassword string, identity *Identity) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		user, err := s.getInactiveByEmail(ctx, tx, email)
+		if err != nil {
+			return err
+		}
+		if err := user.Password.Set(newPassword); err != nil {
+			return err
+		}
+		if err := s.updatePassword(ctx, tx, user); err != nil {
+			return err
+		}
+		user.IsActive = true
+		if err := s.update(ctx, tx, user); err != nil {
+			return err
+		}
+		if err := s.deleteUserInvitations(ctx, tx, user.ID); err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return createIdentity(ctx, tx, identity)
+	})
+}
+
 func (s *UserStore) Activate(ctx context.Context, tokenHash []byte) error {
 	return withTx(s.db, ctx, func(tx *sql.Tx) error {
 		user, err := s.getUserFromInvitation(ctx, tx, tokenHash)
@@ -98,10 +177,124 @@ func (s *UserStore) Activate(ctx context.Context, tokenHash []byte) error {
 	})
 }
 
+// RotateInvitation sustituye las invitaciones de un usuario aún no activado por
+// una nueva. Devuelve ErrNotFound si no hay ningún usuario pendiente con ese email.
+func (s *UserStore) RotateInvitation(ctx context.Context, email string, tokenHash []byte, exp time.Duration) (*User, error) {
+	var user *User
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var err error
+		user, err = s.getInactiveByEmail(ctx, tx, email)
+		if err != nil {
+			return err
+		}
+		if err := s.deleteUserInvitations(ctx, tx, user.ID); err != nil {
+			return err
+		}
+		return s.createUserInvitation(ctx, tx, tokenHash, user.ID, exp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteExpiredInvitations borra las invitaciones caducadas y devuelve cuántas eran.
+func (s *UserStore) DeleteExpiredInvitations(ctx context.Context) (int64, error) {
+	query := `DELETE FROM user_invitations WHERE expiry < $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DeleteUnactivated borra las cuentas que nunca se activaron y se crearon hace
+// más de grace, siempre que no les quede ninguna invitación vigente.
+func (s *UserStore) DeleteUnactivated(ctx context.Context, grace time.Duration) (int64, error) {
+	query := `
+		DELETE FROM users u
+		WHERE u.is_active = FALSE
+			AND u.created_at < $1
+			AND NOT EXISTS (
+				SELECT 1 FROM user_invitations ui WHERE ui.user_id = u.id AND ui.expiry > $2
+			)`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	now := time.Now()
+	res, err := s.db.ExecContext(ctx, query, now.Add(-grace), now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// CreatePasswordReset guarda un token de restablecimiento, sustituyendo a los anteriores del usuario.
+func (s *UserStore) CreatePasswordReset(ctx context.Context, userID int64, tokenHash []byte, exp time.Duration) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.deletePasswordResets(ctx, tx, userID); err != nil {
+			return err
+		}
+		query := `INSERT INTO password_resets (token, user_id, expiry) VALUES ($1, $2, $3)`
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+		_, err := tx.ExecContext(ctx, query, tokenHash, userID, time.Now().Add(exp))
+		return err
+	})
+}
+
+// ResetPassword cambia la contraseña del dueño del token, consume el token
+// y cierra todas sus sesiones, revocando sus refresh tokens y sus tokens
+// personales. Devuelve también los ids de las sesiones cerradas.
+func (s *UserStore) ResetPassword(ctx context.Context, tokenHash []byte, newPassword string) (*User, []string, error) {
+	var user *User
+	var revoked []string
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var err error
+		user, err = s.getUserFromPasswordReset(ctx, tx, tokenHash)
+		if err != nil {
+			return err
+		}
+		if err := user.Password.Set(newPassword); err != nil {
+			return err
+		}
+		if err := s.updatePassword(ctx, tx, user); err != nil {
+			return err
+		}
+		if err := s.deletePasswordResets(ctx, tx, user.ID); err != nil {
+			return err
+		}
+		if revoked, err = revokeUserSessions(ctx, tx, user.ID); err != nil {
+			return err
+		}
+		if err := revokeUserRefreshTokens(ctx, tx, user.ID); err != nil {
+			return err
+		}
+		return deleteUserAccessTokens(ctx, tx, user.ID)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, revoked, nil
+}
+
 func (s *UserStore) GetByID(ctx context.Context, id int64) (*User, error) {
 	query := `
 		SELECT u.id, u.username, u.email, u.password, u.created_at, u.is_active,
-		       r.id, r.name, r.level
+		       COALESCE(u.totp_secret, ''), u.totp_enabled,
+		       u.banned_at, u.ban_reason, u.banned_until,
+		       u.display_name, u.bio, u.avatar_url, u.is_private, u.deleted_at,
+		       r.id, r.name, r.level, r.require_2fa,
+		       ARRAY(
+		           SELECT p.name FROM role_permissions rp
+		           JOIN permissions p ON p.id = rp.permission_id
+		           WHERE rp.role_id = r.id
+		       )
 		FROM users u
 		JOIN roles r ON u.role_id = r.id
 		WHERE u.id = $1`
@@ -112,7 +305,10 @@ func (s *UserStore) GetByID(ctx context.Context, id int64) (*User, error) {
 
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID, &user.Username, &user.Email, &user.Password.hash, &user.CreatedAt, &user.IsActive,
-		&user.Role.ID, &user.Role.Name, &user.Role.Level,
+		&user.TOTPSecret, &user.TwoFactorEnabled,
+		&user.BannedAt, &user.BanReason, &user.BannedUntil,
+		&user.DisplayName, &user.Bio, &user.AvatarURL, &user.IsPrivate, &user.DeletedAt,
+		&user.Role.ID, &user.Role.Name, &user.Role.Level, &user.Role.Require2FA, pq.Array(&user.Role.Permissions),
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -126,7 +322,15 @@ func (s *UserStore) GetByID(ctx context.Context, id int64) (*User, error) {
 func (s *UserStore) GetByEmail(ctx context.Context, email string) (*User, error) {
 	query := `
 		SELECT u.id, u.username, u.email, u.password, u.created_at, u.is_active,
-		       r.id, r.name, r.level
+		       COALESCE(u.totp_secret, ''), u.totp_enabled,
+		       u.banned_at, u.ban_reason, u.banned_until,
+		       u.display_name, u.bio, u.avatar_url, u.is_private, u.deleted_at,
+		       r.id, r.name, r.level, r.require_2fa,
+		       ARRAY(
+		           SELECT p.name FROM role_permissions rp
+		           JOIN permissions p ON p.id = rp.permission_id
+		           WHERE rp.role_id = r.id
+		       )
 		FROM users u
 		JOIN roles r ON u.role_id = r.id
 		WHERE u.email = $1 AND u.is_active = TRUE`
@@ -137,7 +341,10 @@ func (s *UserStore) GetByEmail(ctx context.Context, email string) (*User, error)
 
 	err := s.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID, &user.Username, &user.Email, &user.Password.hash, &user.CreatedAt, &user.IsActive,
-		&user.Role.ID, &user.Role.Name, &user.Role.Level,
+		&user.TOTPSecret, &user.TwoFactorEnabled,
+		&user.BannedAt, &user.BanReason, &user.BannedUntil,
+		&user.DisplayName, &user.Bio, &user.AvatarURL, &user.IsPrivate, &user.DeletedAt,
+		&user.Role.ID, &user.Role.Name, &user.Role.Level, &user.Role.Require2FA, pq.Array(&user.Role.Permissions),
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -215,3 +422,61 @@ func (s *UserStore) deleteUserInvitations(ctx context.Context, tx *sql.Tx, userI
 	_, err := tx.ExecContext(ctx, query, userID)
 	return err
 }
+
+func (s *UserStore) getInactiveByEmail(ctx context.Context, tx *sql.Tx, email string) (*User, error) {
+	query := `
+		SELECT id, username, email, created_at, is_active
+		FROM users
+		WHERE email = $1 AND is_active = FALSE
+		FOR UPDATE`
+
+	var user User
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := tx.QueryRowContext(ctx, query, email).Scan(&user.ID, &user.Username, &user.Email, &user.CreatedAt, &user.IsActive)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *UserStore) getUserFromPasswordReset(ctx context.Context, tx *sql.Tx, tokenHash []byte) (*User, error) {
+	query := `
+		SELECT u.id, u.username, u.email, u.created_at, u.is_active
+		FROM users u
+		JOIN password_resets pr ON u.id = pr.user_id
+		WHERE pr.token = $1 AND pr.expiry > $2`
+
+	var user User
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := tx.QueryRowContext(ctx, query, tokenHash, time.Now()).Scan(&user.ID, &user.Username, &user.Email, &user.CreatedAt, &user.IsActive)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *UserStore) updatePassword(ctx context.Context, tx *sql.Tx, user *User) error {
+	query := `UPDATE users SET password = $1 WHERE id = $2`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	_, err := tx.ExecContext(ctx, query, user.Password.hash, user.ID)
+	return err
+}
+
+func (s *UserStore) deletePasswordResets(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `DELETE FROM password_resets WHERE user_id = $1`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	_, err := tx.ExecContext(ctx, query, userID)
+	return err
+}